@@ -0,0 +1,36 @@
+package main
+
+// visibleSums returns, for each visible folder, the apparent and allocated
+// sizes of the visible folders directly nested in it, skipping any levels
+// hidden by the filter. Shown instead of recursive totals, it tells a folder
+// that is big by itself apart from one whose bulk sits in a listed subfolder.
+func (m model) visibleSums() map[string][2]int64 {
+	rows := m.rows()
+	visible := make(map[string]bool, rows.len())
+	for row := 0; row < rows.len(); row++ {
+		visible[rows.at(row).Path] = true
+	}
+
+	sums := make(map[string][2]int64, rows.len())
+	for row := 0; row < rows.len(); row++ {
+		item := rows.at(row)
+		if item.IsRoot {
+			continue
+		}
+		if parent, ok := m.visibleParent(item.Path, visible); ok {
+			s := sums[parent]
+			sums[parent] = [2]int64{s[0] + item.Size, s[1] + item.Allocated}
+		}
+	}
+	return sums
+}
+
+// withVisibleSize swaps item's sizes for the sums of its visible subfolders
+func withVisibleSize(item Item, sums map[string][2]int64) Item {
+	if item.IsRoot {
+		return item
+	}
+	s := sums[item.Path]
+	item.Size, item.Allocated = s[0], s[1]
+	return item
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// allocatedSize falls back to the apparent size where block counts aren't
+// available
+func allocatedSize(info os.FileInfo) int64 {
+	return info.Size()
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// allocatedSize returns the disk space actually used by the file, which is
+// smaller than its apparent size for sparse files
+func allocatedSize(info os.FileInfo) int64 {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		// st_blocks is always in 512-byte units, whatever the block size
+		return int64(st.Blocks) * 512
+	}
+	return info.Size()
+}
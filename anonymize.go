@@ -0,0 +1,56 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+)
+
+// anonymizer replaces path components with hashes for -anonymize, keeping
+// depth and extensions so an export still shows the tree's shape. The salt
+// is random per export: the same name always maps to the same hash within
+// one export, but common names can't be recovered by hashing guesses.
+// A nil anonymizer leaves paths unchanged.
+type anonymizer struct {
+	salt  []byte
+	names map[string]string
+}
+
+func newAnonymizer() *anonymizer {
+	salt := make([]byte, 16)
+	rand.Read(salt)
+	return &anonymizer{salt: salt, names: map[string]string{}}
+}
+
+// path anonymizes every component of p, keeping its separators
+func (a *anonymizer) path(p string) string {
+	if a == nil {
+		return p
+	}
+	parts := strings.Split(p, string(filepath.Separator))
+	for i, part := range parts {
+		// Empty parts are the separators of absolute paths; keep volume names
+		// such as "C:" so the path stays recognizable as one
+		if part != "" && part != "." && !strings.HasSuffix(part, ":") {
+			parts[i] = a.name(part)
+		}
+	}
+	return strings.Join(parts, string(filepath.Separator))
+}
+
+// name returns the stable replacement of one component
+func (a *anonymizer) name(part string) string {
+	if n, ok := a.names[part]; ok {
+		return n
+	}
+	ext := filepath.Ext(part)
+	if ext == part {
+		ext = "" // a dotfile such as .bashrc has no extension
+	}
+	sum := sha256.Sum256(append(a.salt, part...))
+	n := hex.EncodeToString(sum[:6]) + ext
+	a.names[part] = n
+	return n
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// writeFileAtomic writes a file through write into a temporary file next to
+// path and renames it into place only once everything was written and
+// synced, so a full disk or an interrupted write never leaves a truncated
+// file behind, nor clobbers an existing one.
+func writeFileAtomic(path string, write func(io.Writer) error) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return diskFull(err)
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	bw := bufio.NewWriter(tmp)
+	if err := write(bw); err != nil {
+		return diskFull(err)
+	}
+	if err := bw.Flush(); err != nil {
+		return diskFull(err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return diskFull(err)
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return diskFull(err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// diskFull spells out ENOSPC, the likely failure when running out of space
+// is why diskusage was started
+func diskFull(err error) error {
+	if errors.Is(err, syscall.ENOSPC) {
+		return fmt.Errorf("the disk is full, nothing was written: %w", err)
+	}
+	return err
+}
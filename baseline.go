@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// ratioWidth fits ratios such as "1234×" and the "RATIO ▼" title
+const ratioWidth = 7
+
+// setBaseline makes the item under the cursor the one the RATIO column
+// measures every size against, showing the column; on the baseline itself
+// it clears it again
+func (m model) setBaseline() model {
+	item, ok := m.currentItem()
+	if !ok {
+		return m
+	}
+	if m.baseline != nil && m.baseline.Path == item.Path {
+		m.baseline, m.showRatio = nil, false
+		m.status = "Baseline cleared"
+		return m
+	}
+	m.baseline, m.showRatio = &item, true
+	m.status = fmt.Sprintf("Sizes shown as multiples of %s (%s) - %s hides them", filepath.Base(item.Path), m.formatSize(m.sizeOf(item)), m.keys.short(actRatio))
+	return m
+}
+
+// toggleRatio shows or hides the RATIO column once a baseline is set
+func (m model) toggleRatio() model {
+	if m.baseline == nil {
+		m.status = fmt.Sprintf("No baseline - press %s on an item to compare sizes with it", m.keys.short(actBaseline))
+		return m
+	}
+	m.showRatio = !m.showRatio
+	return m
+}
+
+// rebase looks the baseline up again after a rescan, so the ratios follow
+// its new size, and drops it when it's gone
+func (m model) rebase() model {
+	if m.baseline == nil {
+		return m
+	}
+	for _, items := range []Items{m.files, m.folders} {
+		for _, item := range items {
+			if item.Path == m.baseline.Path {
+				m.baseline = &item
+				return m
+			}
+		}
+	}
+	if m.baseline.Path == m.root.Path {
+		root := m.root
+		m.baseline = &root
+		return m
+	}
+	m.baseline, m.showRatio = nil, false
+	return m
+}
+
+// ratioColumn renders the RATIO cell: the item's size as a multiple of the
+// baseline's, such as "3.2×" or "0.5×"
+func (m model) ratioColumn(item Item) string {
+	base := m.sizeOf(*m.baseline)
+	text := "-"
+	switch size := m.sizeOf(item); {
+	case item.Path == m.baseline.Path:
+		text = "base"
+	case base == 0:
+	case size == 0:
+		text = "0×"
+	default:
+		text = formatRatio(float64(size) / float64(base))
+	}
+	return padLeft(text, ratioWidth)
+}
+
+// formatRatio keeps ratios short: one decimal below 10, whole numbers above
+func formatRatio(r float64) string {
+	switch {
+	case r < 0.1:
+		return "<0.1×"
+	case r < 10:
+		return fmt.Sprintf("%.1f×", r)
+	}
+	return fmt.Sprintf("%.0f×", r)
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+	"time"
+)
+
+// lstatx stats a walked entry; its birth time and compression flag come
+// with the lstat
+func lstatx(path string, d fs.DirEntry) (os.FileInfo, error) {
+	return d.Info()
+}
+
+// birthTime returns when the entry was created, which the stat already
+// done by the walk includes
+func birthTime(path string, info os.FileInfo) (time.Time, bool) {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(st.Birthtimespec.Unix()), true
+	}
+	return time.Time{}, false
+}
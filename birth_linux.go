@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// statxMask asks for everything the scan reads from an entry
+const statxMask = unix.STATX_TYPE | unix.STATX_MODE | unix.STATX_NLINK | unix.STATX_INO |
+	unix.STATX_SIZE | unix.STATX_BLOCKS | unix.STATX_MTIME | unix.STATX_BTIME
+
+// statxInfo is the os.FileInfo of an entry read with a single statx call.
+// Sys returns a *syscall.Stat_t like lstat's, so allocated sizes, devices
+// and hard links are found the same way.
+type statxInfo struct {
+	name  string
+	mode  os.FileMode
+	stat  syscall.Stat_t
+	btime time.Time // zero when the filesystem doesn't record it
+
+	compressed bool // STATX_ATTR_COMPRESSED: the filesystem stores it compressed
+}
+
+func (s *statxInfo) Name() string       { return s.name }
+func (s *statxInfo) Size() int64        { return s.stat.Size }
+func (s *statxInfo) Mode() os.FileMode  { return s.mode }
+func (s *statxInfo) ModTime() time.Time { return time.Unix(s.stat.Mtim.Unix()) }
+func (s *statxInfo) IsDir() bool        { return s.mode.IsDir() }
+func (s *statxInfo) Sys() any           { return &s.stat }
+
+// lstatx stats a walked entry along with its birth time and compression
+// attribute in one statx call. Kernels older than 4.11 don't have statx
+// and get a plain lstat.
+func lstatx(path string, d fs.DirEntry) (os.FileInfo, error) {
+	var stx unix.Statx_t
+	// DONT_SYNC: network filesystems may answer from their cache
+	err := unix.Statx(unix.AT_FDCWD, path, unix.AT_SYMLINK_NOFOLLOW|unix.AT_STATX_DONT_SYNC, statxMask, &stx)
+	if errors.Is(err, unix.ENOSYS) {
+		return d.Info()
+	}
+	if err != nil {
+		return nil, &fs.PathError{Op: "statx", Path: path, Err: err}
+	}
+
+	s := &statxInfo{name: d.Name(), mode: fileMode(uint32(stx.Mode))}
+	setInt(&s.stat.Dev, unix.Mkdev(stx.Dev_major, stx.Dev_minor))
+	setInt(&s.stat.Ino, stx.Ino)
+	setInt(&s.stat.Nlink, uint64(stx.Nlink))
+	setInt(&s.stat.Mode, uint64(stx.Mode))
+	setInt(&s.stat.Size, stx.Size)
+	setInt(&s.stat.Blocks, stx.Blocks)
+	s.stat.Mtim = syscall.NsecToTimespec(time.Unix(stx.Mtime.Sec, int64(stx.Mtime.Nsec)).UnixNano())
+	if stx.Mask&unix.STATX_BTIME != 0 {
+		s.btime = time.Unix(stx.Btime.Sec, int64(stx.Btime.Nsec))
+	}
+	s.compressed = stx.Attributes_mask&stx.Attributes&unix.STATX_ATTR_COMPRESSED != 0
+	return s, nil
+}
+
+// setInt stores v in a Stat_t field, whose integer types differ between
+// architectures
+func setInt[T ~int32 | ~int64 | ~uint32 | ~uint64](field *T, v uint64) {
+	*field = T(v)
+}
+
+// fileMode converts a st_mode to an os.FileMode the way os.Lstat does
+func fileMode(m uint32) os.FileMode {
+	mode := os.FileMode(m & 0o777)
+	switch m & unix.S_IFMT {
+	case unix.S_IFBLK:
+		mode |= os.ModeDevice
+	case unix.S_IFCHR:
+		mode |= os.ModeDevice | os.ModeCharDevice
+	case unix.S_IFDIR:
+		mode |= os.ModeDir
+	case unix.S_IFIFO:
+		mode |= os.ModeNamedPipe
+	case unix.S_IFLNK:
+		mode |= os.ModeSymlink
+	case unix.S_IFSOCK:
+		mode |= os.ModeSocket
+	}
+	if m&unix.S_ISGID != 0 {
+		mode |= os.ModeSetgid
+	}
+	if m&unix.S_ISUID != 0 {
+		mode |= os.ModeSetuid
+	}
+	if m&unix.S_ISVTX != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode
+}
+
+// birthTime returns when the entry at path was created. Entries the walk
+// read with lstatx already carry it; others, such as link targets,
+// ask statx for just the birth time. Older kernels and filesystems that
+// don't record it, such as ext3 or tmpfs on some kernels, report none.
+func birthTime(path string, info os.FileInfo) (time.Time, bool) {
+	if s, ok := info.(*statxInfo); ok {
+		return s.btime, !s.btime.IsZero()
+	}
+	var stx unix.Statx_t
+	if err := unix.Statx(unix.AT_FDCWD, path, unix.AT_SYMLINK_NOFOLLOW|unix.AT_STATX_DONT_SYNC, unix.STATX_BTIME, &stx); err != nil {
+		return time.Time{}, false
+	}
+	if stx.Mask&unix.STATX_BTIME == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(stx.Btime.Sec, int64(stx.Btime.Nsec)), true
+}
@@ -0,0 +1,20 @@
+//go:build !linux && !darwin && !windows
+
+package main
+
+import (
+	"io/fs"
+	"os"
+	"time"
+)
+
+// lstatx stats a walked entry, which carries no birth time or compression
+// flag here
+func lstatx(path string, d fs.DirEntry) (os.FileInfo, error) {
+	return d.Info()
+}
+
+// birthTime reports no creation time where the platform doesn't expose one
+func birthTime(path string, info os.FileInfo) (time.Time, bool) {
+	return time.Time{}, false
+}
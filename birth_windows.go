@@ -0,0 +1,23 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+	"time"
+)
+
+// lstatx stats a walked entry; its birth time and compression attribute
+// come with the listing
+func lstatx(path string, d fs.DirEntry) (os.FileInfo, error) {
+	return d.Info()
+}
+
+// birthTime returns when the entry was created, which the directory
+// listing already includes
+func birthTime(path string, info os.FileInfo) (time.Time, bool) {
+	if attrs, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return time.Unix(0, attrs.CreationTime.Nanoseconds()), true
+	}
+	return time.Time{}, false
+}
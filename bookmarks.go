@@ -0,0 +1,134 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// rescanMsg carries the result of scanning a bookmarked folder
+type rescanMsg struct {
+	res scanResult
+	err error
+}
+
+// configFile returns the path of a diskusage file in the user config
+// directory
+func configFile(name string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "diskusage", name), nil
+}
+
+// loadBookmarks reads the pinned folders, one per line. A missing or
+// unreadable file just means there are none yet.
+func loadBookmarks() []string {
+	file, err := configFile("bookmarks")
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+func saveBookmarks(paths []string) error {
+	file, err := configFile("bookmarks")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, []byte(strings.Join(paths, "\n")+"\n"), 0o644)
+}
+
+// toggleBookmark pins the folder being browsed, or unpins it if it already is
+func (m model) toggleBookmark() model {
+	if m.opts.fromNDJSON {
+		m.status = "Bookmarks need a local scan"
+		return m
+	}
+	dir := m.dir()
+	if i := slices.Index(m.bookmarks, dir); i >= 0 {
+		m.bookmarks = slices.Delete(slices.Clone(m.bookmarks), i, i+1)
+		m.status = "Unpinned " + dir
+	} else {
+		m.bookmarks = append(slices.Clone(m.bookmarks), dir)
+		m.status = "Pinned " + dir
+	}
+	if err := saveBookmarks(m.bookmarks); err != nil {
+		m.status = "Can't save bookmarks: " + err.Error()
+	}
+	return m
+}
+
+// nextBookmark rescans the bookmark after the current folder, cycling
+// through them in the order they were pinned
+func (m model) nextBookmark() (model, tea.Cmd) {
+	if m.opts.fromNDJSON {
+		m.status = "Bookmarks need a local scan"
+		return m, nil
+	}
+	if len(m.bookmarks) == 0 {
+		m.status = "No bookmarks - press m to pin the current folder"
+		return m, nil
+	}
+	return m.scanPath(m.bookmarks[(slices.Index(m.bookmarks, m.dir())+1)%len(m.bookmarks)])
+}
+
+// withScan replaces the browsed data with a fresh scan of another folder,
+// keeping the display settings. A snapshot comparison and the root typed for
+// -as-typed only apply to the original root, so they're dropped.
+func (m model) withScan(res scanResult) model {
+	if res.root != m.basePath {
+		m.opts.typedRoot = ""
+	}
+	m.basePath = res.root
+	m.root = Item{Path: res.root, Size: res.rootSize, Allocated: res.rootAlloc, ItemCount: res.rootCount, Inodes: res.rootInodes, SizePartial: res.rootPartial, SizePending: res.rootPending, IsDir: true, IsRoot: true}
+	m.files, m.folders = res.files, res.folders
+	if m.opts.rootRow {
+		m.folders = append(Items{m.root}, m.folders...)
+	}
+	m.combined = combine(m.files, m.folders)
+	m.total, m.totalAlloc = res.total, res.totalAlloc
+	m.scanErrors, m.errOffset = res.errors, 0
+	m.scanTime = res.elapsed
+	m.opts.compare = ""
+	m.checksums = nil
+	m.sizing = nil
+
+	m.scope, m.navStack, m.filter = "", nil, ""
+	m.cursor, m.offset, m.confirming = 0, 0, false
+	m = m.detectVolume().rebase()
+	return m.setSort(m.sortKey, m.sortDesc)
+}
+
+// detectVolume looks up the filesystem the scan root is on
+func (m model) detectVolume() model {
+	m.readOnlyFS, m.mount, m.volume = false, nil, nil
+	if m.opts.fromNDJSON {
+		return m
+	}
+	m.readOnlyFS = readOnlyMount(m.basePath)
+	if mi, ok := findMount(m.basePath); ok {
+		m.volume = &mi
+		if mi.mountPoint == m.basePath {
+			m.mount = &mi
+		}
+	}
+	return m
+}
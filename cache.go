@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// cacheVersion changes whenever cachedDir gains fields older caches lack
+const cacheVersion = 2
+
+// sizeCache remembers the recursive size of each scanned folder along with
+// its modification time, so a rescan only re-walks folders that changed.
+// Files rewritten in place don't change their folder's mtime, so each folder
+// also keeps a fingerprint of the files directly in it, checked against the
+// ones the walk just listed; -no-cache always walks everything.
+type sizeCache struct {
+	Version     int                  `json:"version"`     // cacheVersion of the format; older caches are discarded
+	DirOverhead bool                 `json:"dirOverhead"` // sizes only apply to scans with the same settings
+	Virtual     bool                 `json:"virtual"`
+	Ignore      []string             `json:"ignore,omitempty"`
+	Symlinks    string               `json:"symlinks,omitempty"`
+	Compressed  bool                 `json:"compressed,omitempty"`
+	Dirs        map[string]cachedDir `json:"dirs"`
+}
+
+// cachedDir is the cached summary of one folder
+type cachedDir struct {
+	ModTime   time.Time   `json:"modTime"`
+	Files     uint64      `json:"files"` // fileFingerprints of the files directly inside
+	Size      int64       `json:"size"`
+	Allocated int64       `json:"allocated"`
+	Count     int         `json:"count"`
+	Inodes    int         `json:"inodes"`
+	Largest   *cachedFile `json:"largest,omitempty"`
+}
+
+// cachedFile is the biggest file below a cached folder
+type cachedFile struct {
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	Allocated int64     `json:"allocated"`
+	ModTime   time.Time `json:"modTime"`
+}
+
+// cacheFile returns the path of the size cache in the user cache directory
+func cacheFile() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "diskusage", "sizes.json"), nil
+}
+
+// loadSizeCache reads the cache. A missing or unreadable cache, or one
+// written with different -dir-overhead, -include-virtual, -symlinks or
+// -compressed settings or another ignore list, is treated as empty.
+func loadSizeCache(opts scanOptions) sizeCache {
+	empty := sizeCache{Version: cacheVersion, DirOverhead: opts.dirOverhead, Virtual: opts.virtual, Ignore: opts.ignore, Symlinks: opts.symlinks, Compressed: opts.compressed, Dirs: map[string]cachedDir{}}
+	file, err := cacheFile()
+	if err != nil {
+		return empty
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return empty
+	}
+	var c sizeCache
+	if json.Unmarshal(data, &c) != nil || c.Version != cacheVersion || c.DirOverhead != opts.dirOverhead || c.Virtual != opts.virtual || !slices.Equal(c.Ignore, opts.ignore) || c.Symlinks != opts.symlinks || c.Compressed != opts.compressed || c.Dirs == nil {
+		return empty
+	}
+	return c
+}
+
+// fileFingerprints sums up the name, size, allocated size and mtime of the
+// files directly inside each folder, keyed by the folder's path. The sum
+// doesn't depend on the order the files are listed in.
+func fileFingerprints(files Items) map[string]uint64 {
+	prints := map[string]uint64{}
+	for _, f := range files {
+		h := fnv.New64a()
+		fmt.Fprintf(h, "%s\x00%d\x00%d\x00%d", filepath.Base(f.Path), f.Size, f.Allocated, f.ModTime.UnixNano())
+		prints[filepath.Dir(f.Path)] += h.Sum64()
+	}
+	return prints
+}
+
+// fill copies cached sizes into dirs whose whole subtree is unchanged and
+// reports which ones it filled. dirs must hold the scan root first and
+// every folder below it, as collected by the walk, and prints the
+// fileFingerprints of the files it listed.
+func (c sizeCache) fill(root string, dirs Items, prints map[string]uint64) []bool {
+	index := make(map[string]int, len(dirs))
+	for i, dir := range dirs {
+		index[dir.Path] = i
+	}
+
+	// A folder whose mtime changed has entries added, removed or renamed, and
+	// one whose fingerprint changed has files rewritten in place, so it and
+	// every folder above it need sizing again
+	dirty := make([]bool, len(dirs))
+	for _, dir := range dirs {
+		if cached, ok := c.Dirs[dir.Path]; ok && cached.ModTime.Equal(dir.ModTime) && cached.Files == prints[dir.Path] {
+			continue
+		}
+		for p := dir.Path; ; p = filepath.Dir(p) {
+			j, ok := index[p]
+			if !ok || dirty[j] {
+				break
+			}
+			dirty[j] = true
+			if p == root {
+				break
+			}
+		}
+	}
+
+	filled := make([]bool, len(dirs))
+	for i := range dirs {
+		if dirty[i] {
+			continue
+		}
+		cached := c.Dirs[dirs[i].Path]
+		dirs[i].Size, dirs[i].Allocated, dirs[i].ItemCount, dirs[i].Inodes = cached.Size, cached.Allocated, cached.Count, cached.Inodes
+		if f := cached.Largest; f != nil {
+			dirs[i].LargestChild = &Item{Path: f.Path, Size: f.Size, Allocated: f.Allocated, ModTime: f.ModTime}
+		}
+		filled[i] = true
+	}
+	return filled
+}
+
+// newCachedDir summarizes a sized folder for the cache
+func newCachedDir(dir Item, prints map[string]uint64) cachedDir {
+	cd := cachedDir{ModTime: dir.ModTime, Files: prints[dir.Path], Size: dir.Size, Allocated: dir.Allocated, Count: dir.ItemCount, Inodes: dir.Inodes}
+	if f := dir.LargestChild; f != nil {
+		cd.Largest = &cachedFile{Path: f.Path, Size: f.Size, Allocated: f.Allocated, ModTime: f.ModTime}
+	}
+	return cd
+}
+
+// update replaces the cached folders under root with the ones just sized,
+// leaving out those that failed or were only partly readable, as access may
+// be granted without changing their modification time, and writes the
+// cache back
+func (c sizeCache) update(root string, dirs Items, prints map[string]uint64, errs []error) error {
+	for path := range c.Dirs {
+		if path == root || strings.HasPrefix(path, root+string(filepath.Separator)) {
+			delete(c.Dirs, path)
+		}
+	}
+	for i, dir := range dirs {
+		if errs[i] == nil && !dir.SizePartial {
+			c.Dirs[dir.Path] = newCachedDir(dir, prints)
+		}
+	}
+	return c.save()
+}
+
+// checkpoint saves the folders sized so far by a scan still in progress, so
+// an interrupted scan resumes from them instead of starting over. Folders
+// still waiting to be sized lose their old entry: a subfolder may be saved
+// with its new size while they aren't, and their stale total would then look
+// current to the next scan.
+func (c sizeCache) checkpoint(dirs Items, prints map[string]uint64, filled, done []bool) error {
+	for i, dir := range dirs {
+		switch {
+		case done[i] && !dir.SizePartial:
+			c.Dirs[dir.Path] = newCachedDir(dir, prints)
+		case !filled[i]:
+			delete(c.Dirs, dir.Path)
+		}
+	}
+	return c.save()
+}
+
+// save writes the cache file
+func (c sizeCache) save() error {
+	file, err := cacheFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	// Written then renamed, so a concurrent scan never reads a partial cache
+	return writeFileAtomic(file, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Checksum algorithms for -checksum
+const (
+	checksumCRC32  = "crc32"  // fast, catches accidental corruption
+	checksumSHA256 = "sha256" // slower, safe for integrity checks
+)
+
+func validChecksum(algo string) bool {
+	return algo == checksumCRC32 || algo == checksumSHA256
+}
+
+func newHash(algo string) hash.Hash {
+	if algo == checksumCRC32 {
+		return crc32.NewIEEE()
+	}
+	return sha256.New()
+}
+
+// checksumWidth is how many hex digits the CHECKSUM column shows; the
+// details view has the full SHA-256
+func (m model) checksumWidth() int {
+	if m.opts.checksum == checksumCRC32 {
+		return 8
+	}
+	return 16
+}
+
+// checksumColumn renders the CHECKSUM cell, blank until the item is hashed
+func (m model) checksumColumn(item Item) string {
+	sum := m.checksums[item.Path]
+	return padRight(sum[:min(len(sum), m.checksumWidth())], m.checksumWidth())
+}
+
+var errHashCanceled = errors.New("canceled")
+
+// hashProgressMsg reports how far running checksums have got. ch delivers
+// the next progress or the final hashDoneMsg; closing cancel stops them.
+type hashProgressMsg struct {
+	done, total int64
+	ch          <-chan tea.Msg
+	cancel      chan struct{}
+}
+
+// hashDoneMsg carries the checksums computed before finishing or canceling
+type hashDoneMsg struct {
+	sums     map[string]string
+	failed   []scanError
+	canceled bool
+}
+
+// hashSelected checksums the selected files, or the file under the cursor
+// when nothing is selected, in the background
+func (m model) hashSelected() (model, tea.Cmd) {
+	if m.hashing != nil {
+		m.status = "Checksums are already running - Esc cancels them"
+		return m, nil
+	}
+	if m.opts.fromNDJSON {
+		m.status = "Checksums need a local scan"
+		return m, nil
+	}
+	var items Items
+	for _, item := range m.selectedItems() {
+		if !item.IsDir {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		if item, ok := m.currentItem(); ok && !item.IsDir && item.deletable() {
+			items = Items{item}
+		}
+	}
+	if len(items) == 0 {
+		m.status = "Select files to checksum - folders are skipped"
+		return m, nil
+	}
+
+	var total int64
+	for _, item := range items {
+		total += item.Size
+	}
+	algo := m.opts.checksum
+	ch := make(chan tea.Msg, 1)
+	cancel := make(chan struct{})
+	go func() {
+		var done int64
+		var last time.Time
+		progress := func(n int64) {
+			done += n
+			if time.Since(last) < copyProgressInterval {
+				return
+			}
+			last = time.Now()
+			select {
+			case ch <- hashProgressMsg{done: done, total: total, ch: ch, cancel: cancel}:
+			default:
+			}
+		}
+
+		msg := hashDoneMsg{sums: map[string]string{}}
+		for _, item := range items {
+			sum, err := hashFile(item.Path, algo, cancel, progress)
+			if errors.Is(err, errHashCanceled) {
+				msg.canceled = true
+				break
+			}
+			if err != nil {
+				msg.failed = append(msg.failed, scanError{path: item.Path, err: fmt.Errorf("checksum: %w", err)})
+				continue
+			}
+			msg.sums[item.Path] = sum
+		}
+		ch <- msg
+	}()
+
+	m.hashing = &hashProgressMsg{total: total, ch: ch, cancel: cancel}
+	return m, waitForCopy(ch)
+}
+
+// hashFile returns the hex checksum of path, checking for cancellation
+// between reads
+func hashFile(path, algo string, cancel <-chan struct{}, progress func(n int64)) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := newHash(algo)
+	buf := make([]byte, 256*1024)
+	for {
+		select {
+		case <-cancel:
+			return "", errHashCanceled
+		default:
+		}
+		n, err := f.Read(buf)
+		h.Write(buf[:n])
+		progress(int64(n))
+		if err == io.EOF {
+			return hex.EncodeToString(h.Sum(nil)), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// cancelHashing stops running checksums; those already computed are kept
+func (m model) cancelHashing() model {
+	select {
+	case <-m.hashing.cancel:
+	default:
+		close(m.hashing.cancel)
+	}
+	m.status = "Canceling checksums..."
+	return m
+}
+
+// finishHash stores the new checksums and logs the files that failed
+func (m model) finishHash(msg hashDoneMsg) model {
+	m.hashing = nil
+	if m.checksums == nil {
+		m.checksums = map[string]string{}
+	}
+	for path, sum := range msg.sums {
+		m.checksums[path] = sum
+	}
+	m.status = fmt.Sprintf("%s of %d files computed", m.opts.checksum, len(msg.sums))
+	if msg.canceled {
+		m.status += " - canceled"
+	}
+	if len(msg.failed) > 0 {
+		m.scanErrors = append(m.scanErrors, msg.failed...)
+		m.status += fmt.Sprintf(", %d failed - press %s for details", len(msg.failed), m.keys.short(actErrors))
+	}
+	return m
+}
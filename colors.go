@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// The palette. Each color is given for truecolor terminals along with its
+// closest xterm-256 entry and a basic ANSI color picked for contrast rather
+// than closeness, so the UI stays readable over a 16-color SSH session
+// instead of leaving the terminal to approximate the hex codes. lipgloss
+// picks the variant matching the detected terminal, or -colors.
+var (
+	colorWhite     = lipgloss.CompleteColor{TrueColor: "#FFF", ANSI256: "15", ANSI: "15"}
+	colorBlack     = lipgloss.CompleteColor{TrueColor: "#000", ANSI256: "16", ANSI: "0"}
+	colorTitle     = lipgloss.CompleteColor{TrueColor: "#0366d6", ANSI256: "26", ANSI: "4"}
+	colorHeader    = lipgloss.CompleteColor{TrueColor: "#2f363d", ANSI256: "237", ANSI: "8"}
+	colorCursor    = lipgloss.CompleteColor{TrueColor: "#2ea043", ANSI256: "34", ANSI: "2"}
+	colorSize      = lipgloss.CompleteColor{TrueColor: "#58a6ff", ANSI256: "75", ANSI: "12"}
+	colorMuted     = lipgloss.CompleteColor{TrueColor: "#8b949e", ANSI256: "246", ANSI: "7"}
+	colorError     = lipgloss.CompleteColor{TrueColor: "#f85149", ANSI256: "203", ANSI: "9"}
+	colorDanger    = lipgloss.CompleteColor{TrueColor: "#da3633", ANSI256: "160", ANSI: "1"}
+	colorMark      = lipgloss.CompleteColor{TrueColor: "#ff0000", ANSI256: "196", ANSI: "9"}
+	colorGrowth    = lipgloss.CompleteColor{TrueColor: "#3fb950", ANSI256: "71", ANSI: "10"}
+	colorHighlight = lipgloss.CompleteColor{TrueColor: "#d29922", ANSI256: "172", ANSI: "3"}
+	colorPurple    = lipgloss.CompleteColor{TrueColor: "#bc8cff", ANSI256: "141", ANSI: "13"}
+	colorCyan      = lipgloss.CompleteColor{TrueColor: "#39c5cf", ANSI256: "44", ANSI: "14"}
+	colorPink      = lipgloss.CompleteColor{TrueColor: "#ff9bce", ANSI256: "218", ANSI: "5"}
+)
+
+// colorProfiles are the values of -colors besides "auto"
+var colorProfiles = map[string]termenv.Profile{
+	"truecolor": termenv.TrueColor,
+	"256":       termenv.ANSI256,
+	"16":        termenv.ANSI,
+	"none":      termenv.Ascii,
+}
+
+// setColorProfile overrides the detected color support, for terminals that
+// report more than they can show, such as some multiplexers and serial
+// consoles. "auto" keeps what lipgloss detected, which honors NO_COLOR.
+func setColorProfile(name string) error {
+	if name == "auto" {
+		return nil
+	}
+	profile, ok := colorProfiles[name]
+	if !ok {
+		return fmt.Errorf("invalid -colors %q: use auto, truecolor, 256, 16 or none", name)
+	}
+	lipgloss.SetColorProfile(profile)
+	return nil
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// columns holds the widths of each column of the item list
+type columns struct {
+	selectWidth int // Width for selection indicator (including brackets) [*]
+	sizeWidth   int // Fixed width for size column
+	extras      []extraColumn
+	nameWidth   int
+	pathWidth   int
+}
+
+// extraColumn is an optional column shown between SIZE and NAME
+type extraColumn struct {
+	title string
+	width int
+	key   sortKey
+	cell  func(m model, item Item) string // padded to width, possibly styled
+}
+
+// countWidth fits "9,999,999" entries
+const countWidth = 9
+
+// createdWidth fits a "2006-01-02" date and the "CREATED ▼" title
+const createdWidth = 10
+
+// depthWidth fits the "DEPTH ▼" title
+const depthWidth = 7
+
+// extraColumns returns the optional columns enabled in the current view
+func (m model) extraColumns() []extraColumn {
+	var cols []extraColumn
+	if m.opts.compare != "" {
+		cols = append(cols, extraColumn{"DELTA", m.deltaWidth(), sortByDelta, model.deltaColumn})
+	}
+	if m.opts.bytes {
+		cols = append(cols, extraColumn{"BYTES", bytesWidth, sortBySize, model.bytesColumn})
+	}
+	if m.opts.depth {
+		cols = append(cols, extraColumn{"DEPTH", depthWidth, sortByDepth, model.depthColumn})
+	}
+	if m.opts.counts {
+		cols = append(cols, extraColumn{"ITEMS", countWidth, sortByCount, model.countColumn})
+	}
+	if m.opts.avg {
+		cols = append(cols, extraColumn{"AVG", m.sizeWidth(), sortByAvg, model.avgColumn})
+	}
+	if m.showRatio && m.baseline != nil {
+		cols = append(cols, extraColumn{"RATIO", ratioWidth, sortBySize, model.ratioColumn})
+	}
+	if len(m.checksums) > 0 {
+		cols = append(cols, extraColumn{"CHECKSUM", m.checksumWidth(), sortNone, model.checksumColumn})
+	}
+	if m.opts.inodes {
+		cols = append(cols, extraColumn{"INODES", countWidth, sortByInodes, model.inodeColumn})
+	}
+	if m.opts.scan.created {
+		cols = append(cols, extraColumn{"CREATED", createdWidth, sortByCreated, model.createdColumn})
+	}
+	return cols
+}
+
+// countColumn renders the ITEMS cell, left blank for files; the loose row
+// counts the files it sums up
+func (m model) countColumn(item Item) string {
+	text := ""
+	if item.IsDir || item.IsLoose {
+		text = m.loc.comma(int64(item.ItemCount))
+	}
+	return padLeft(text, countWidth)
+}
+
+// avgSize is a folder's size divided by the entries it contains, telling a
+// single huge file apart from millions of small ones. A file is its own
+// average, so files sort among folders by their size.
+func (item Item) avgSize(allocated bool) int64 {
+	size := item.Size
+	if allocated {
+		size = item.Allocated
+	}
+	if !item.IsDir {
+		return size
+	}
+	if item.ItemCount == 0 {
+		return 0
+	}
+	return size / int64(item.ItemCount)
+}
+
+// avgColumn renders the AVG cell, left blank for files and empty folders
+func (m model) avgColumn(item Item) string {
+	text := ""
+	if item.IsDir && item.ItemCount > 0 {
+		text = m.formatSize(item.avgSize(m.opts.allocated))
+	}
+	return padLeft(text, m.sizeWidth())
+}
+
+// inodeColumn renders the INODES cell, left blank for files
+func (m model) inodeColumn(item Item) string {
+	text := ""
+	if item.IsDir {
+		text = m.loc.comma(int64(item.Inodes))
+	}
+	return padLeft(text, countWidth)
+}
+
+// createdColumn renders the CREATED cell, left blank where the filesystem
+// doesn't record birth times
+func (m model) createdColumn(item Item) string {
+	text := ""
+	if !item.Created.IsZero() {
+		text = item.Created.Format("2006-01-02")
+	}
+	return padLeft(text, createdWidth)
+}
+
+// columns calculates column widths based on screen size
+func (m model) columns() columns {
+	c := columns{selectWidth: 3, sizeWidth: m.sizeWidth(), extras: m.extraColumns()}
+	if m.opts.percent {
+		c.sizeWidth += percentWidth + 1
+	}
+	minPathWidth := 30 // Minimum width for path
+	extraWidth := 0    // Optional columns between size and name
+	for _, col := range c.extras {
+		extraWidth += col.width + 1
+	}
+	c.nameWidth = m.width - c.sizeWidth - c.selectWidth - extraWidth - minPathWidth - 6 // -6 for spacing
+
+	// If we still have too much space, limit name column to something reasonable
+	if c.nameWidth > 100 {
+		c.nameWidth = 100
+	}
+
+	// Path gets whatever is left
+	c.pathWidth = m.width - c.sizeWidth - c.nameWidth - c.selectWidth - extraWidth - 6
+	return c
+}
+
+// headerLine renders the column titles after the selection column
+func (m model) headerLine(c columns) string {
+	extra := ""
+	for _, col := range c.extras {
+		extra += padLeft(col.title+m.sortIndicator(col.key), col.width) + " "
+	}
+	name := "NAME" + m.sortIndicator(sortByName)
+	if m.sortKey == sortByExt {
+		name = "NAME.EXT" + m.sortIndicator(sortByExt)
+	}
+	return fmt.Sprintf("%s %s%s %s",
+		padLeft("SIZE"+m.sortIndicator(sortBySize), c.sizeWidth),
+		extra,
+		padRight(name, c.nameWidth),
+		"PATH"+m.sortIndicator(sortByPath),
+	)
+}
+
+// itemLine renders an item's columns after the selection column. Columns
+// are padded by their visible width, so styling never misaligns them.
+func (m model) itemLine(item Item, c columns) string {
+	name := printable(filepath.Base(item.Path))
+	relPath := printable(m.displayPath(filepath.Dir(item.Path)))
+	suffix := ""
+	if m.opts.dirSlash && item.IsDir && !item.IsRoot {
+		suffix += string(filepath.Separator)
+	}
+	if item.IsRoot {
+		suffix += " (total)"
+		relPath = printable(m.displayPath(item.Path))
+	}
+	if item.isSparse() {
+		suffix += " (sparse)"
+	}
+	if item.Compressed {
+		suffix += " (compressed)"
+	}
+	if item.IsMount {
+		suffix += " (mount)"
+	}
+	if item.LinkTarget {
+		suffix += " (link, target size)"
+	} else if item.IsLink {
+		suffix += " (link)"
+	}
+	if item.SizePartial {
+		suffix += " (partial)"
+	}
+	if item.SizePending {
+		suffix += " (calculating...)"
+	}
+	if !item.IsRoot && ignoredBy(m.ignore, item.Path) {
+		suffix += " (ignored)"
+	}
+	if item.IsLoose {
+		// Markers of the folder whose path it borrows don't apply to the files
+		name, suffix = looseName, ""
+		relPath = printable(m.displayPath(item.Path))
+	}
+
+	extra := ""
+	for _, col := range c.extras {
+		extra += col.cell(m, item) + " "
+	}
+
+	indent := m.treeIndent(item)
+	indent = indent[:max(min(len(indent), c.nameWidth/2), 0)]
+	nameMatches, pathMatches := m.columnMatches(item)
+	if m.opts.typedRoot != "" {
+		// the matches are in the path relative to the folder being browsed,
+		// which follows that folder's typed form in the column
+		shift := len(printable(m.displayPath(m.dir()))) + len(string(filepath.Separator))
+		for i := range pathMatches {
+			pathMatches[i] = span{pathMatches[i].start + shift, pathMatches[i].end + shift}
+		}
+	}
+	nameText := m.fitName(name, suffix, nameMatches, c.nameWidth-len(indent))
+	pathText := m.fitColumn(relPath, pathMatches, c.pathWidth, true)
+
+	return fmt.Sprintf("%s %s%s %s",
+		m.styles.size.Render(padLeft(m.sizeText(item), c.sizeWidth)),
+		extra,
+		padRight(indent+nameText, c.nameWidth),
+		pathText,
+	)
+}
+
+// minNameWidth is the least of a name kept visible before its markers are
+// shortened along with it
+const minNameWidth = 8
+
+// fitName fits a name and its markers into width, shortening the name rather
+// than cutting off the trailing / or a tag such as "(mount)"
+func (m model) fitName(name, suffix string, matches []span, width int) string {
+	room := width - len(suffix)
+	if room < minNameWidth {
+		return m.fitColumn(name+suffix, matches, width, false)
+	}
+	return m.fitColumn(name, matches, room, false) + suffix
+}
+
+// keyAt returns the sort key of the column under screen column x
+func (c columns) keyAt(x int) (sortKey, bool) {
+	x -= c.selectWidth + 1 // "[ ] "
+	if x < 0 {
+		return 0, false
+	}
+	if x < c.sizeWidth+1 {
+		return sortBySize, true
+	}
+	x -= c.sizeWidth + 1
+	for _, col := range c.extras {
+		if x < col.width+1 {
+			return col.key, col.key != sortNone
+		}
+		x -= col.width + 1
+	}
+	if x < c.nameWidth+1 {
+		return sortByName, true
+	}
+	return sortByPath, true
+}
+
+// depth returns how many levels below the base path an item sits, numbered
+// like du's --max-depth: immediate children are at depth 1
+func (m model) depth(item Item) int {
+	rel := getRelativePath(item.Path, m.basePath)
+	if rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// maxDepth returns the deepest level in the active list
+func (m model) maxDepth() int {
+	deepest := 0
+	for _, item := range m.activeItems() {
+		deepest = max(deepest, m.depth(item))
+	}
+	return deepest
+}
+
+// depthColumn renders the DEPTH cell
+func (m model) depthColumn(item Item) string {
+	return fmt.Sprintf("%*d", depthWidth, m.depth(item))
+}
@@ -0,0 +1,31 @@
+package main
+
+import "path/filepath"
+
+// combine merges files and folders into the single list of the combined
+// view. The root summary row only belongs to the folders view.
+func combine(files, folders Items) Items {
+	all := make(Items, 0, len(files)+len(folders))
+	all = append(all, files...)
+	for _, item := range folders {
+		if !item.IsRoot {
+			all = append(all, item)
+		}
+	}
+	return all
+}
+
+// topLevel reports whether item sits directly in the folder being browsed,
+// which is all the combined view lists
+func (m model) topLevel(item Item) bool {
+	return filepath.Dir(item.Path) == m.dir()
+}
+
+// nextView returns the view Tab switches to
+func nextView(mode string) string {
+	return map[string]string{
+		"files":    "folders",
+		"folders":  "combined",
+		"combined": "files",
+	}[mode]
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commandLine returns a diskusage invocation that reproduces the current
+// view, including options toggled interactively
+func (m model) commandLine() string {
+	o := m.opts
+	args := []string{"diskusage"}
+	flagIf := func(on bool, name string) {
+		if on {
+			args = append(args, "-"+name)
+		}
+	}
+	value := func(name, v, def string) {
+		if v != def {
+			args = append(args, "-"+name, shellQuote(v))
+		}
+	}
+
+	flagIf(o.rootRow, "root")
+	flagIf(o.secure, "secure")
+	value("protect", o.protect, "")
+	flagIf(o.readonly, "readonly")
+	flagIf(o.anonymize, "anonymize")
+	flagIf(o.inline, "inline")
+	value("page-size", strconv.Itoa(o.pageSize), "0")
+	flagIf(o.refreshOnFocus, "refresh-on-focus")
+	flagIf(o.progressive, "progressive")
+	value("compare", o.compare, "")
+	flagIf(o.allocated && !o.scan.compressed, "allocated")
+	flagIf(o.scan.compressed, "compressed")
+	value("locale", o.locale, "")
+	flagIf(o.counts, "counts")
+	flagIf(o.avg, "avg")
+	flagIf(o.inodes, "inodes")
+	flagIf(m.viewMode == "combined", "combined")
+	flagIf(o.shallow, "shallow")
+	flagIf(o.pareto, "pareto")
+	value("warn-at", strconv.Itoa(o.warnAt), strconv.Itoa(defaultWarnAt))
+	value("pareto-percent", strconv.Itoa(o.paretoPercent), strconv.Itoa(defaultParetoPercent))
+	flagIf(o.histogram, "histogram")
+	flagIf(o.caseSensitive, "case-sensitive")
+	value("units", o.units, unitsSI)
+	value("colors", o.colors, "auto")
+	flagIf(o.bytes, "bytes")
+	flagIf(o.percent, "percent")
+	flagIf(!o.dirSlash, "dir-slash=false")
+	flagIf(o.depth, "depth")
+	value("sort", m.sortSpec(), "size")
+	flagIf(o.scan.dirOverhead, "dir-overhead")
+	flagIf(o.scan.noCache, "no-cache")
+	flagIf(o.noIgnore, "no-ignore")
+	value("deletion-log", o.deletionLog, defaultDeletionLog())
+	flagIf(o.noLog, "no-log")
+	flagIf(o.timing, "timing")
+	flagIf(o.scan.virtual, "include-virtual")
+	value("symlinks", o.scan.symlinks, symlinksLink)
+	flagIf(o.scan.created, "created")
+	flagIf(o.asTyped, "as-typed")
+	value("quota", o.quota, "")
+	value("since", o.since, "")
+	value("until", o.until, "")
+	value("concurrency", strconv.Itoa(o.scan.workers), strconv.Itoa(defaultScanOptions().workers))
+	value("checksum", o.checksum, checksumSHA256)
+	value("exec", o.exec, "")
+
+	if o.fromNDJSON {
+		return strings.Join(append(args, "-from-ndjson"), " ")
+	}
+	return strings.Join(append(args, shellQuote(m.basePath)), " ")
+}
+
+// shellQuote quotes s for a POSIX shell unless it only has safe characters
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:,=%+@") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// copyToClipboard sets the terminal clipboard with an OSC 52 sequence. It is
+// written to stderr so it can't interleave with the renderer on stdout.
+func copyToClipboard(s string) tea.Cmd {
+	return func() tea.Msg {
+		seq := osc52.New(s)
+		if os.Getenv("TMUX") != "" {
+			seq = seq.Tmux()
+		}
+		seq.WriteTo(os.Stderr)
+		return nil
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// compressedSize returns the space the file takes on disk and whether APFS
+// or HFS+ stores it compressed. The block count already covers the
+// compressed data, and the flag comes with the listing.
+func compressedSize(path string, info os.FileInfo) (int64, bool) {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return allocatedSize(info), st.Flags&unix.UF_COMPRESSED != 0
+	}
+	return allocatedSize(info), false
+}
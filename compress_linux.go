@@ -0,0 +1,13 @@
+package main
+
+import "os"
+
+// compressedSize returns the space the file takes on disk and whether the
+// filesystem stores it compressed, as the walk's statx reported. The size
+// is st_blocks either way: ZFS counts compressed blocks there, while btrfs
+// only reports its compressed extents to root through its own ioctls, so
+// its compressed files are marked but keep their allocated size.
+func compressedSize(path string, info os.FileInfo) (int64, bool) {
+	s, ok := info.(*statxInfo)
+	return allocatedSize(info), ok && s.compressed
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !windows
+
+package main
+
+import "os"
+
+// compressedSize reports the allocated size where the platform doesn't say
+// how a file is stored
+func compressedSize(path string, info os.FileInfo) (int64, bool) {
+	return allocatedSize(info), false
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var procGetCompressedFileSize = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetCompressedFileSizeW")
+
+// invalidFileSize is the low word GetCompressedFileSize returns on failure,
+// which is only an error when the last error is set too
+const invalidFileSize = 0xFFFFFFFF
+
+// compressedSize returns the space the file at path takes on disk, as
+// GetCompressedFileSize reports it for NTFS-compressed and sparse files,
+// and whether it carries the compressed attribute. Without the call the
+// apparent size stands in, as everywhere on Windows.
+func compressedSize(path string, info os.FileInfo) (int64, bool) {
+	compressed := false
+	if attrs, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		compressed = attrs.FileAttributes&windows.FILE_ATTRIBUTE_COMPRESSED != 0
+	}
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return info.Size(), compressed
+	}
+	var high uint32
+	low, _, err := procGetCompressedFileSize.Call(uintptr(unsafe.Pointer(name)), uintptr(unsafe.Pointer(&high)))
+	if uint32(low) == invalidFileSize && err != windows.ERROR_SUCCESS {
+		return info.Size(), compressed
+	}
+	return int64(high)<<32 | int64(uint32(low)), compressed
+}
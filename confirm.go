@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// confirmGroup is the selected items sharing one parent directory
+type confirmGroup struct {
+	dir   string
+	items Items
+	size  int64
+}
+
+// selectedItems returns what a confirmed delete would remove: every selected,
+// deletable item in the active list, including ones hidden by the filter
+func (m model) selectedItems() Items {
+	var out Items
+	for _, item := range m.activeItems() {
+		if m.isSelected(item) && item.deletable() {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// confirmGroups groups the selection by parent directory, largest first
+func (m model) confirmGroups() []confirmGroup {
+	index := map[string]int{}
+	var groups []confirmGroup
+	for _, item := range m.selectedItems() {
+		dir := filepath.Dir(item.Path)
+		i, ok := index[dir]
+		if !ok {
+			i = len(groups)
+			index[dir] = i
+			groups = append(groups, confirmGroup{dir: dir})
+		}
+		groups[i].items = append(groups[i].items, item)
+		groups[i].size += m.sizeOf(item)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].size != groups[j].size {
+			return groups[i].size > groups[j].size
+		}
+		return groups[i].dir < groups[j].dir
+	})
+	return groups
+}
+
+// freedByDelete estimates the disk space deleting the selection gives back:
+// the allocated size of each item, counting items inside a selected folder
+// once. Hard links elsewhere and files held open keep their space, so the
+// real figure can be lower.
+func (m model) freedByDelete() int64 {
+	items := m.selectedItems()
+	selected := make(map[string]bool, len(items))
+	for _, item := range items {
+		selected[item.Path] = true
+	}
+	var freed int64
+	for _, item := range items {
+		nested := false
+		for dir := filepath.Dir(item.Path); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+			if selected[dir] {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			freed += item.Allocated
+		}
+	}
+	return freed
+}
+
+// inUse lists the selected files, or files inside selected folders, that
+// running processes hold open, with the names of those processes
+func (m model) inUse() map[string][]string {
+	open := openFiles()
+	if len(open) == 0 {
+		return nil
+	}
+	found := map[string][]string{}
+	for _, item := range m.selectedItems() {
+		for path, procs := range open {
+			if path == item.Path || item.IsDir && strings.HasPrefix(path, item.Path+string(filepath.Separator)) {
+				found[item.Path] = appendNew(found[item.Path], procs...)
+			}
+		}
+	}
+	return found
+}
+
+// appendNew appends the names not already in list
+func appendNew(list []string, names ...string) []string {
+	for _, n := range names {
+		if !slices.Contains(list, n) {
+			list = append(list, n)
+		}
+	}
+	return list
+}
+
+// confirmView renders the delete or move confirmation, listing the selection
+// by parent directory with subtotals so scattered changes are easy to review
+func (m model) confirmView() string {
+	groups := m.confirmGroups()
+	count, total := 0, int64(0)
+	for _, g := range groups {
+		count += len(g.items)
+		total += g.size
+	}
+
+	open, protected := m.openSelected, len(m.protected) > 0
+	if m.transfer != nil {
+		open, protected = nil, false // only checked when deleting
+	}
+
+	var s strings.Builder
+	title := " Disk Usage Analyzer - CONFIRM DELETE "
+	if m.transfer != nil {
+		title = " Disk Usage Analyzer - CONFIRM " + strings.ToUpper(m.transfer.verb()) + " "
+	}
+	s.WriteString(m.styles.title.Render(title) + "\n")
+
+	// Title, blank line, summary, free space and prompt take 7 lines, plus
+	// one each for the in-use and protected warnings; items beyond that are
+	// summarized so the prompt always stays on screen
+	budget := max(m.height-7, 3)
+	if len(open) > 0 {
+		budget = max(budget-1, 3)
+	}
+	if protected {
+		budget = max(budget-1, 3)
+	}
+	shown := 0
+	sizeWidth := m.sizeWidth()
+	for _, g := range groups {
+		if budget <= 1 {
+			break
+		}
+		dir := printable(getRelativePath(g.dir, m.basePath)) + string(filepath.Separator)
+		s.WriteString("\n" + m.styles.header.Render(fmt.Sprintf("%s %s (%d)", padLeft(m.formatSize(g.size), sizeWidth), dir, len(g.items))))
+		budget--
+		for _, item := range g.items {
+			if budget == 0 {
+				break
+			}
+			line := "  " + padLeft(m.formatSize(m.sizeOf(item)), sizeWidth) + " " + printable(filepath.Base(item.Path))
+			if procs := open[item.Path]; len(procs) > 0 {
+				line += " (in use by " + printable(strings.Join(procs, ", ")) + ")"
+			}
+			s.WriteString("\n" + m.styles.normal.Render(line))
+			budget--
+			shown++
+		}
+	}
+	if rest := count - shown; rest > 0 {
+		s.WriteString("\n" + m.styles.helpText.Render(fmt.Sprintf("  ... and %d more", rest)))
+	}
+
+	s.WriteString("\n\n" + m.styles.size.Render(fmt.Sprintf("Total: %d items in %d folders, %s", count, len(groups), m.formatSize(total))))
+	if m.transfer == nil && m.volume != nil {
+		after := m.volume.free + m.freedByDelete()
+		s.WriteString("\n" + m.styles.helpText.Render(fmt.Sprintf("Free space: %s now, about %s after deleting (estimate)",
+			m.formatSize(m.volume.free), m.formatSize(after))))
+	}
+	if n := len(open); n > 0 {
+		var procs []string
+		for _, p := range open {
+			procs = appendNew(procs, p...)
+		}
+		slices.Sort(procs)
+		verb := "are"
+		if n == 1 {
+			verb = "is"
+		}
+		s.WriteString("\n" + m.styles.errorText.Render(fmt.Sprintf("Warning: %d of the selected items %s held open by %s, which may misbehave after the delete",
+			n, verb, strings.Join(procs, ", "))))
+	}
+	if protected {
+		s.WriteString("\n" + m.styles.errorText.Render(m.protectedWarning()))
+	}
+	prompt := "Delete these items?"
+	switch {
+	case m.transfer != nil:
+		prompt = m.transfer.verb() + " these items to " + m.transfer.dest + "?"
+	case protected && m.protectAck:
+		prompt = "Really delete the protected files too? Confirm once more"
+	case m.opts.secure:
+		prompt = "Securely wipe and delete these items?"
+	}
+	prompt += " (" + m.keys.short(actConfirm, actCancel) + ")"
+	s.WriteString("\n" + m.styles.confirmText.Render(prompt))
+
+	block := lipgloss.JoinVertical(lipgloss.Left, strings.Split(s.String(), "\n")...)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, block)
+}
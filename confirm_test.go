@@ -0,0 +1,27 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestConfirmShowsFreeSpaceBelowMountPoint(t *testing.T) {
+	m := resize(testModel(t, 3), 100, 40)
+	if m.volume == nil {
+		t.Skip("no volume details here")
+	}
+	if m.mount != nil {
+		t.Fatalf("%s is a mount point; the test needs a folder below one", m.basePath)
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if !m.confirming {
+		t.Fatal("d didn't ask to confirm the delete")
+	}
+	if view := m.confirmView(); !strings.Contains(view, "Free space:") {
+		t.Errorf("confirmation doesn't estimate the free space:\n%s", view)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// copyProgressInterval limits how often a running copy redraws the screen
+const copyProgressInterval = 100 * time.Millisecond
+
+// copyProgressMsg reports how far a running copy has got. ch delivers the
+// next progress or the final copyDoneMsg.
+type copyProgressMsg struct {
+	done, total int64
+	ch          <-chan tea.Msg
+}
+
+// copyDoneMsg reports the outcome of copying the selection
+type copyDoneMsg struct {
+	dest   string
+	copied int
+	failed []scanError
+}
+
+// waitForCopy waits for the next message of a background copy or checksum
+func waitForCopy(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg { return <-ch }
+}
+
+// copySelected copies the selection into dest in the background, sending
+// progress back through Update
+func (m model) copySelected(dest string) tea.Cmd {
+	items := m.outerSelected()
+	var total int64
+	for _, item := range items {
+		total += item.Size
+	}
+
+	// Progress updates are dropped while one is still waiting to be drawn,
+	// so a slow terminal never holds up the copy
+	ch := make(chan tea.Msg, 1)
+	go func() {
+		var done int64
+		var last time.Time
+		progress := func(n int64) {
+			done += n
+			if time.Since(last) < copyProgressInterval {
+				return
+			}
+			last = time.Now()
+			select {
+			case ch <- copyProgressMsg{done: done, total: total, ch: ch}:
+			default:
+			}
+		}
+
+		msg := copyDoneMsg{dest: dest}
+		for _, item := range items {
+			if err := copyItem(item.Path, filepath.Join(dest, filepath.Base(item.Path)), progress); err != nil {
+				msg.failed = append(msg.failed, scanError{path: item.Path, err: fmt.Errorf("copy: %w", err)})
+				continue
+			}
+			msg.copied++
+		}
+		ch <- msg
+	}()
+
+	return func() tea.Msg { return copyProgressMsg{total: total, ch: ch} }
+}
+
+// finishCopy reports the copy and logs what couldn't be copied
+func (m model) finishCopy(msg copyDoneMsg) model {
+	m.status = fmt.Sprintf("Copied %d items to %s", msg.copied, msg.dest)
+	if len(msg.failed) > 0 {
+		m.scanErrors = append(m.scanErrors, msg.failed...)
+		m.status += fmt.Sprintf(", %d failed - press %s for details", len(msg.failed), m.keys.short(actErrors))
+	}
+	return m
+}
+
+// progressView renders the progress bar of a running copy or checksum
+func (m model) progressView(verb string, done, total int64) string {
+	frac := 1.0
+	if total > 0 && done < total {
+		frac = float64(done) / float64(total)
+	}
+	width := max(min(m.width-40, 40), 10)
+	filled := int(frac * float64(width))
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+	return m.styles.helpText.Render(fmt.Sprintf("%s %s / %s ", verb, m.formatSize(done), m.formatSize(total))) +
+		m.styles.size.Render(bar) +
+		m.styles.helpText.Render(fmt.Sprintf(" %3.0f%%", frac*100))
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCopyItem(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	ro := filepath.Join(src, "ro")
+	if err := os.MkdirAll(ro, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ro, "file"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("missing", filepath.Join(src, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+	stamp := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(ro, stamp, stamp); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(ro, 0o555); err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(root, "dst")
+	t.Cleanup(func() {
+		os.Chmod(ro, 0o755)
+		os.Chmod(filepath.Join(dst, "ro"), 0o755)
+	})
+
+	var written int64
+	if err := copyItem(src, dst, func(n int64) { written += n }); err != nil {
+		t.Fatalf("copyItem: %v", err)
+	}
+	if written != 5 {
+		t.Errorf("progress reported %d bytes, want 5", written)
+	}
+
+	link, err := os.Readlink(filepath.Join(dst, "dangling"))
+	if err != nil {
+		t.Fatalf("dangling link not copied: %v", err)
+	}
+	if link != "missing" {
+		t.Errorf("link points to %q, want %q", link, "missing")
+	}
+
+	info, err := os.Stat(filepath.Join(dst, "ro"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != 0o555 {
+		t.Errorf("folder mode %v, want %v", got, os.FileMode(0o555))
+	}
+	if !info.ModTime().Equal(stamp) {
+		t.Errorf("folder mtime %v, want %v", info.ModTime(), stamp)
+	}
+	data, err := os.ReadFile(filepath.Join(dst, "ro", "file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("file holds %q, want %q", data, "hello")
+	}
+
+	if err := copyItem(src, dst, nil); err == nil {
+		t.Error("copying onto an existing path succeeded")
+	}
+}
+
+func TestCopyIntoSelectionRefused(t *testing.T) {
+	m := treeModel(t)
+	a := filepath.Join(m.basePath, "a")
+	m = m.setSelected(a, true)
+	m.viewMode = "folders"
+	for _, dest := range []string{a, filepath.Join(a, "b")} {
+		p := m.promptTransfer(true)
+		p, _ = p.prompt.onSubmit(p, dest)
+		if p.transfer != nil || !strings.Contains(p.status, "inside the selected") {
+			t.Errorf("copying into %s: status %q, want it refused", dest, p.status)
+		}
+	}
+}
+
+func TestCopySkipsNestedSelection(t *testing.T) {
+	m := treeModel(t)
+	a := filepath.Join(m.basePath, "a")
+	m = m.setSelected(a, true).setSelected(filepath.Join(a, "small"), true)
+	m.viewMode = "combined"
+	dest := t.TempDir()
+
+	msg := m.copySelected(dest)()
+	for {
+		progress, ok := msg.(copyProgressMsg)
+		if !ok {
+			break
+		}
+		msg = <-progress.ch
+	}
+	done := msg.(copyDoneMsg)
+	if done.copied != 1 || len(done.failed) != 0 {
+		t.Fatalf("copied %d items with failures %v, want a alone", done.copied, done.failed)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "small")); err == nil {
+		t.Error("a/small was copied on its own as well")
+	}
+	if _, err := os.Stat(filepath.Join(dest, "a", "small")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMoveSkipsNestedSelection(t *testing.T) {
+	m := treeModel(t)
+	a, small := filepath.Join(m.basePath, "a"), filepath.Join(m.basePath, "a", "small")
+	m = m.setSelected(a, true).setSelected(small, true)
+	m.viewMode = "combined"
+	dest := t.TempDir()
+
+	done := m.moveSelected(dest)().(moveDoneMsg)
+	if len(done.moved) != 1 || done.moved[0] != a || len(done.failed) != 0 {
+		t.Fatalf("moved %q with failures %v, want a alone", done.moved, done.failed)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "a", "small")); err != nil {
+		t.Error(err)
+	}
+	if m = m.finishMove(done); len(m.selected) != 0 {
+		t.Errorf("%d items still selected after moving them", len(m.selected))
+	}
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const readOnlyMessage = "Read-only mode: deletion is disabled"
+
+// deletionDisabled explains why deleting isn't possible, or returns ""
+func (m model) deletionDisabled() string {
+	switch {
+	case m.opts.readonly:
+		return readOnlyMessage
+	case m.opts.fromNDJSON:
+		return "Deletion is disabled for imported scans"
+	case m.readOnlyFS:
+		return "The filesystem is mounted read-only: deletion is disabled"
+	}
+	return ""
+}
+
+// deleteWorkers bounds how many items are removed at once, so one slow
+// unlink on a network filesystem doesn't hold up the rest of the batch
+const deleteWorkers = 8
+
+// deleteProgressMsg reports how much of a running deletion is done. ch
+// delivers the next progress or the final deleteDoneMsg.
+type deleteProgressMsg struct {
+	done, total int64
+	ch          <-chan tea.Msg
+}
+
+// deleteDoneMsg reports the outcome of deleting the selection, the items in
+// the order they were listed
+type deleteDoneMsg struct {
+	deleted []Item
+	failed  []deleteFailure
+}
+
+// deleteFailure is an item that couldn't be removed
+type deleteFailure struct {
+	item Item
+	err  error
+}
+
+// deleteSelected removes the selected items of the active list in the
+// background, several at a time. Items inside a selected folder go with it
+// rather than racing its removal.
+func (m model) deleteSelected() (model, tea.Cmd) {
+	m.sudo = nil
+	items := m.outerSelected()
+	var total int64
+	for _, item := range items {
+		total += item.Size
+	}
+
+	// Progress updates are dropped while one is still waiting to be drawn,
+	// as with copies
+	ch := make(chan tea.Msg, 1)
+	go func() {
+		errs := make([]error, len(items))
+		next := make(chan int)
+		var mu sync.Mutex
+		var done int64
+		var last time.Time
+		var wg sync.WaitGroup
+		for range min(deleteWorkers, len(items)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range next {
+					errs[i] = m.opts.removeItem(items[i])
+					mu.Lock()
+					done += items[i].Size
+					if time.Since(last) >= copyProgressInterval {
+						last = time.Now()
+						select {
+						case ch <- deleteProgressMsg{done: done, total: total, ch: ch}:
+						default:
+						}
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+		for i := range items {
+			next <- i
+		}
+		close(next)
+		wg.Wait()
+
+		var msg deleteDoneMsg
+		for i, item := range items {
+			if errs[i] != nil {
+				msg.failed = append(msg.failed, deleteFailure{item, errs[i]})
+				continue
+			}
+			msg.deleted = append(msg.deleted, item)
+		}
+		ch <- msg
+	}()
+
+	m.deleting = &deleteProgressMsg{total: total, ch: ch}
+	return m, waitForCopy(ch)
+}
+
+// outerSelected returns the selected items of the active list that aren't
+// inside another selected folder, as those go along with it
+func (m model) outerSelected() Items {
+	selected := m.selectedItems()
+	paths := make(map[string]bool, len(selected))
+	for _, item := range selected {
+		paths[item.Path] = true
+	}
+	var items Items
+	for _, item := range selected {
+		if !selectedAncestor(item.Path, paths) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// selectedAncestor reports whether a folder above path is among paths
+func selectedAncestor(path string, paths map[string]bool) bool {
+	for dir := filepath.Dir(path); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if paths[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// finishDelete records what a deletion removed, deselecting it along with
+// anything selected inside a removed folder and taking it out of the lists,
+// and sums up the batch. Failures
+// go to the error log; a single one refused for lack of permission offers a
+// sudo retry as before.
+func (m model) finishDelete(msg deleteDoneMsg) model {
+	m.deleting = nil
+	var freed int64
+	now := time.Now()
+	method := removedPermanent
+	if m.opts.secure {
+		method = removedSecure
+	}
+	logged := make([]deletion, 0, len(msg.deleted))
+	for _, item := range msg.deleted {
+		logged = append(logged, deletion{Path: item.Path, Size: item.Size, Allocated: item.Allocated, Time: now, Method: method})
+		freed += item.Allocated
+	}
+	m.deleted = append(m.deleted, logged...)
+	removed := make(map[string]bool, len(msg.deleted))
+	for _, item := range msg.deleted {
+		removed[item.Path] = true
+	}
+	for _, item := range m.selectedItems() {
+		if removed[item.Path] || selectedAncestor(item.Path, removed) {
+			m = m.setSelected(item.Path, false)
+		}
+	}
+	m = m.forgetDeleted(msg.deleted, removed)
+
+	m.status = fmt.Sprintf("%d deleted, %d failed, %s freed", len(msg.deleted), len(msg.failed), m.formatSize(freed))
+	for _, f := range msg.failed {
+		m.scanErrors = append(m.scanErrors, scanError{path: f.item.Path, err: fmt.Errorf("delete: %w", f.err)})
+	}
+	if len(msg.failed) > 0 {
+		m.status += fmt.Sprintf(" - press %s for details", m.keys.short(actErrors))
+	}
+	m = m.logDeletions(logged)
+	if len(msg.failed) == 1 {
+		if handled, ok := m.permissionFailure(msg.failed[0].item, msg.failed[0].err); ok {
+			m = handled
+		}
+	}
+	// Free space changed, so the full-disk banner is rechecked
+	return m.detectVolume()
+}
+
+// forgetDeleted takes the deleted items, and everything inside deleted
+// folders, out of the lists, and subtracts them from the folders above
+// them, the root and the grand totals. removed holds their paths.
+func (m model) forgetDeleted(deleted Items, removed map[string]bool) model {
+	if len(deleted) == 0 {
+		return m
+	}
+	type shrink struct {
+		size, allocated int64
+		count, inodes   int
+	}
+	shrunk := map[string]shrink{}
+	for _, item := range deleted {
+		s := shrink{size: item.Size, allocated: item.Allocated, count: 1, inodes: 1}
+		if item.IsDir {
+			s.count, s.inodes = item.ItemCount+1, item.Inodes
+		}
+		for dir := filepath.Dir(item.Path); ; dir = filepath.Dir(dir) {
+			t := shrunk[dir]
+			t.size, t.allocated, t.count, t.inodes = t.size+s.size, t.allocated+s.allocated, t.count+s.count, t.inodes+s.inodes
+			shrunk[dir] = t
+			if dir == m.basePath || dir == filepath.Dir(dir) {
+				break
+			}
+		}
+		m.total -= item.Size
+		m.totalAlloc -= item.Allocated
+	}
+
+	gone := func(path string) bool { return removed[path] || selectedAncestor(path, removed) }
+	resize := func(item Item) Item {
+		if s, ok := shrunk[item.Path]; ok && item.IsDir {
+			item.Size, item.Allocated = item.Size-s.size, item.Allocated-s.allocated
+			item.ItemCount, item.Inodes = item.ItemCount-s.count, item.Inodes-s.inodes
+		}
+		if f := item.LargestChild; f != nil && gone(f.Path) {
+			item.LargestChild = nil
+		}
+		return item
+	}
+	// New slices, as the other pane of a split screen may share the old ones
+	keep := func(items Items) Items {
+		kept := make(Items, 0, len(items))
+		for _, item := range items {
+			if !gone(item.Path) {
+				kept = append(kept, resize(item))
+			}
+		}
+		return kept
+	}
+	m.root = resize(m.root)
+	m.files, m.folders, m.combined = keep(m.files), keep(m.folders), keep(m.combined)
+	m = m.rebase().setSort(m.sortKey, m.sortDesc)
+	return m.clampView()
+}
+
+// removeItem deletes a file, or a folder with everything in it, overwriting
+// regular files first when secure deletion was requested
+func (o options) removeItem(item Item) error {
+	if item.IsDir {
+		return o.removeDir(item.Path)
+	}
+	if o.secure {
+		return secureDelete(item.Path)
+	}
+	return os.Remove(item.Path)
+}
+
+// removeDir deletes a folder tree. With secure deletion every regular file
+// in it is wiped before the tree is removed. A folder that's already gone,
+// for instance inside another selected folder, isn't an error.
+func (o options) removeDir(path string) error {
+	if o.secure {
+		err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.Mode().IsRegular() {
+				return secureDelete(p)
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.RemoveAll(path)
+}
+
+// secureDelete overwrites a regular file with zeros, truncates it and then
+// removes it. Anything that isn't a regular file is removed as-is.
+//
+// This is best-effort only: SSD wear levelling, copy-on-write filesystems
+// (btrfs, ZFS, APFS) and snapshots may keep the original blocks around.
+func secureDelete(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return os.Remove(path)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+
+	zeros := make([]byte, 64*1024)
+	remaining := info.Size()
+	for remaining > 0 {
+		n := int64(len(zeros))
+		if remaining < n {
+			n = remaining
+		}
+		if _, err := f.Write(zeros[:n]); err != nil {
+			f.Close()
+			return err
+		}
+		remaining -= n
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Truncate(0); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// treeModel builds a model over a folder holding a/b/big (100 bytes),
+// a/small (10) and top (5)
+func treeModel(t *testing.T) model {
+	t.Helper()
+	isolateUserDirs(t)
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a", "b", "big"), 100)
+	writeFile(t, filepath.Join(dir, "a", "small"), 10)
+	writeFile(t, filepath.Join(dir, "top"), 5)
+	m, err := initialModel(dir, options{scan: defaultScanOptions(), sort: "size"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resize(m, 100, 40)
+}
+
+// waitForDelete runs the deletion m started until it's done
+func waitForDelete(t *testing.T, m model) deleteDoneMsg {
+	t.Helper()
+	if m.deleting == nil {
+		t.Fatal("no deletion running")
+	}
+	for msg := range m.deleting.ch {
+		if done, ok := msg.(deleteDoneMsg); ok {
+			return done
+		}
+	}
+	t.Fatal("deletion ended without reporting")
+	return deleteDoneMsg{}
+}
+
+func TestDeleteForgetsRemovedItems(t *testing.T) {
+	m := treeModel(t)
+	b := filepath.Join(m.basePath, "a", "b")
+	m = m.setSelected(b, true)
+	m.viewMode = "folders"
+	m, _ = m.deleteSelected()
+	if p := m.promptTransfer(true); p.prompt != nil {
+		t.Error("offered to copy the selection while it's being deleted")
+	}
+	m = m.finishDelete(waitForDelete(t, m))
+
+	for _, items := range []Items{m.files, m.folders, m.combined} {
+		for _, item := range items {
+			if item.Path == b || strings.HasPrefix(item.Path, b+string(filepath.Separator)) {
+				t.Errorf("%s is still listed after deleting its folder", item.Path)
+			}
+		}
+	}
+	for _, f := range m.folders {
+		if f.Path == filepath.Join(m.basePath, "a") && (f.Size != 10 || f.ItemCount != 1) {
+			t.Errorf("a holds %d bytes in %d items, want 10 in 1", f.Size, f.ItemCount)
+		}
+	}
+	if m.root.Size != 15 || m.total != 15 {
+		t.Errorf("root size %d and total %d, want 15", m.root.Size, m.total)
+	}
+}
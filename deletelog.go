@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Ways a deletion removed an item, as recorded in the deletion log. Nothing
+// goes to a trash: every deletion is permanent.
+const (
+	removedPermanent = "permanent" // removed outright
+	removedSecure    = "secure"    // overwritten with zeros, then removed
+	removedSudo      = "sudo"      // removed outright with sudo rm after a permission error
+)
+
+// defaultDeletionLog is deletions.log under the user's state directory:
+// $XDG_STATE_HOME, or ~/.local/state where the platform has no such place of
+// its own. It's "" if no home directory is known.
+func defaultDeletionLog() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if !filepath.IsAbs(dir) {
+		var err error
+		if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+			dir, err = os.UserConfigDir()
+		} else if dir, err = os.UserHomeDir(); err == nil {
+			dir = filepath.Join(dir, ".local", "state")
+		}
+		if err != nil {
+			return ""
+		}
+	}
+	return filepath.Join(dir, "diskusage", "deletions.log")
+}
+
+// appendDeletions adds deletions to the log at file, one JSON object per
+// line, creating it and its folder as needed
+func appendDeletions(file string, ds []deletion) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, d := range ds {
+		if err := enc.Encode(d); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// logDeletions records ds in the deletion log unless -no-log turned it off.
+// The deletions have happened either way, so a log that can't be written is
+// only reported on the status line.
+func (m model) logDeletions(ds []deletion) model {
+	if m.opts.noLog || m.opts.deletionLog == "" || len(ds) == 0 {
+		return m
+	}
+	if err := appendDeletions(m.opts.deletionLog, ds); err != nil {
+		m.status += " - can't write the deletion log: " + err.Error()
+	}
+	return m
+}
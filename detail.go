@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// exactSize spells out the byte counts of one item for the status line,
+// leaving the units of the list alone
+func (m model) exactSize(item Item) string {
+	name := filepath.Base(item.Path)
+	if item.IsLoose {
+		name = looseName
+	}
+	return fmt.Sprintf("%s: %s bytes apparent, %s bytes allocated",
+		name, m.loc.comma(item.Size), m.loc.comma(item.Allocated))
+}
+
+// detailView renders the full-screen details of the item under the cursor
+func (m model) detailView() string {
+	item, _ := m.currentItem()
+
+	labelStyle := m.styles.size.Width(12)
+	row := func(label, value string) string {
+		return "  " + labelStyle.Render(label) + m.styles.normal.Render(printable(value)) + "\n"
+	}
+	sizeText := func(n int64) string {
+		return fmt.Sprintf("%s (%s bytes)", m.loc.bytes(uint64(n)), m.loc.comma(n))
+	}
+
+	var s strings.Builder
+	s.WriteString(m.styles.title.Render(" Disk Usage Analyzer - DETAILS ") + "\n\n")
+	s.WriteString(row("Path", item.Path))
+	if item.IsLoose {
+		s.WriteString(row("Files", fmt.Sprintf("%s directly in this folder, not in its subfolders", m.loc.comma(int64(item.ItemCount)))))
+	}
+	s.WriteString(row("Apparent", sizeText(item.Size)))
+	s.WriteString(row("Allocated", sizeText(item.Allocated)))
+	if !item.ModTime.IsZero() {
+		s.WriteString(row("Modified", fmt.Sprintf("%s (%s)", m.loc.relTime(item.ModTime), item.ModTime.Format("2006-01-02 15:04"))))
+	}
+	if !item.Created.IsZero() {
+		s.WriteString(row("Created", fmt.Sprintf("%s (%s)", m.loc.relTime(item.Created), item.Created.Format("2006-01-02 15:04"))))
+	}
+	if sum, ok := m.checksums[item.Path]; ok {
+		s.WriteString(row("Checksum", m.opts.checksum+" "+sum))
+	}
+	if largest := item.LargestChild; largest != nil {
+		s.WriteString(row("Biggest", fmt.Sprintf("%s (%s)", getRelativePath(largest.Path, item.Path), sizeText(largest.Size))))
+	}
+	if item.IsMount {
+		s.WriteString(row("Mount point", "yes - its size is that of another filesystem"))
+	}
+	if item.SizePartial {
+		s.WriteString(row("Partial", "yes - some of it couldn't be read, so it's bigger than shown"))
+	}
+	if item.LinkTarget {
+		s.WriteString(row("Symlink", "yes - sized as the file it points to"))
+	} else if item.IsLink {
+		s.WriteString(row("Symlink", "yes - sized as the link itself"))
+	}
+	if item.Compressed {
+		s.WriteString(row("Compressed", "yes - on disk it takes only its allocated size"))
+	}
+	if item.isSparse() {
+		s.WriteString(row("Sparse", "yes - deleting it frees only the allocated size"))
+	}
+	s.WriteString(m.styles.helpText.Render("\n" + m.keys.short(actInfo) + "/Esc: Close details"))
+
+	block := lipgloss.JoinVertical(lipgloss.Left, strings.Split(s.String(), "\n")...)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, block)
+}
+
+// largestPeek names the biggest file below the folder under the cursor, for
+// the status line
+func (m model) largestPeek() string {
+	item, ok := m.currentItem()
+	if !ok || !item.IsDir || item.LargestChild == nil {
+		return ""
+	}
+	largest := *item.LargestChild
+	return fmt.Sprintf("Largest file inside: %s (%s)", getRelativePath(largest.Path, item.Path), m.formatSize(m.sizeOf(largest)))
+}
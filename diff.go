@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// diffTopRemoved is how many of the largest removed files the diff lists
+const diffTopRemoved = 10
+
+// snapshotMsg carries an in-session snapshot of the scan root
+type snapshotMsg struct {
+	snap snapshot
+	err  error
+}
+
+// scanDiff summarizes what changed between two snapshots of the same root
+type scanDiff struct {
+	removed, added, changed int
+	freed                   int64 // bytes of removed files plus shrinkage
+	grown                   int64 // bytes of added files plus growth
+	net                     int64 // change in the root's total size
+	largestRemoved          []snapshotEntry
+}
+
+// takeSnapshot rescans the root in the background for an in-session diff
+func (m model) takeSnapshot() (model, tea.Cmd) {
+	if m.opts.fromNDJSON {
+		m.status = "Snapshots need a local scan"
+		return m, nil
+	}
+	m.status = "Taking snapshot..."
+	root, opts := m.basePath, m.opts
+	return m, func() tea.Msg {
+		res, err := scanDirectory(root, opts.scan)
+		if err != nil {
+			return snapshotMsg{err: err}
+		}
+		return snapshotMsg{snap: newSnapshot(res.root, res.rootSize, res.files, res.folders)}
+	}
+}
+
+// addSnapshot keeps the first snapshot as the baseline and shows the diff
+// against it for every later one
+func (m model) addSnapshot(msg snapshotMsg) model {
+	if msg.err != nil {
+		m.status = "Can't take snapshot: " + msg.err.Error()
+		return m
+	}
+	if m.snapBefore == nil {
+		m.snapBefore = &msg.snap
+		m.status = fmt.Sprintf("Snapshot taken - clean up, then press %s again to see what changed", m.keys.short(actSnapshot))
+		return m
+	}
+	m.snapAfter = &msg.snap
+	m.showDiff = true
+	return m
+}
+
+// diffSnapshots compares the files of two snapshots
+func diffSnapshots(before, after snapshot) scanDiff {
+	d := scanDiff{net: after.RootSize - before.RootSize}
+	now := make(map[string]int64, len(after.Files))
+	for _, e := range after.Files {
+		now[e.Path] = e.Size
+	}
+	for _, e := range before.Files {
+		size, ok := now[e.Path]
+		switch {
+		case !ok:
+			d.removed++
+			d.freed += e.Size
+			d.largestRemoved = append(d.largestRemoved, e)
+		case size < e.Size:
+			d.changed++
+			d.freed += e.Size - size
+		case size > e.Size:
+			d.changed++
+			d.grown += size - e.Size
+		}
+		delete(now, e.Path)
+	}
+	for _, size := range now {
+		d.added++
+		d.grown += size
+	}
+
+	sort.Slice(d.largestRemoved, func(i, j int) bool {
+		return d.largestRemoved[i].Size > d.largestRemoved[j].Size
+	})
+	if len(d.largestRemoved) > diffTopRemoved {
+		d.largestRemoved = d.largestRemoved[:diffTopRemoved]
+	}
+	return d
+}
+
+// diffView renders the full-screen summary of what changed between the two
+// in-session snapshots
+func (m model) diffView() string {
+	d := diffSnapshots(*m.snapBefore, *m.snapAfter)
+
+	labelStyle := m.styles.size.Width(12)
+	row := func(label, value string) string {
+		return "  " + labelStyle.Render(label) + m.styles.normal.Render(printable(value)) + "\n"
+	}
+	signed := func(n int64) string {
+		if n > 0 {
+			return "+" + m.formatSize(n)
+		}
+		if n < 0 {
+			return "-" + m.formatSize(-n)
+		}
+		return m.formatSize(0)
+	}
+
+	var s strings.Builder
+	s.WriteString(m.styles.title.Render(" Disk Usage Analyzer - WHAT CHANGED ") + "\n\n")
+	s.WriteString(row("Between", fmt.Sprintf("%s and %s", m.snapBefore.Created.Format("15:04:05"), m.snapAfter.Created.Format("15:04:05"))))
+	s.WriteString(row("Freed", m.formatSize(d.freed)))
+	s.WriteString(row("Removed", fmt.Sprintf("%d files", d.removed)))
+	s.WriteString(row("Added", fmt.Sprintf("%d files, %s", d.added, m.formatSize(d.grown))))
+	s.WriteString(row("Changed", fmt.Sprintf("%d files", d.changed)))
+	net := signed(d.net)
+	if d.net < 0 {
+		net = m.styles.deltaDown.Render(net)
+	} else if d.net > 0 {
+		net = m.styles.deltaUp.Render(net)
+	}
+	s.WriteString("  " + labelStyle.Render("Net") + net + "\n")
+
+	if len(d.largestRemoved) > 0 {
+		s.WriteString("\n" + m.styles.header.Padding(0, 1).Render("Largest removed") + "\n")
+		for _, e := range d.largestRemoved {
+			size := padLeft(m.formatSize(e.Size), m.sizeWidth())
+			s.WriteString("  " + m.styles.size.Render(size) + " " + m.styles.normal.Render(truncateFromStart(printable(filepath.ToSlash(e.Path)), max(m.width-m.sizeWidth()-4, 10))) + "\n")
+		}
+	}
+	s.WriteString(m.styles.helpText.Render(fmt.Sprintf("\n%s: Compare again • Esc: Close", m.keys.short(actSnapshot))))
+
+	block := lipgloss.JoinVertical(lipgloss.Left, strings.Split(s.String(), "\n")...)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, block)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// scanError is a problem hit while scanning that didn't stop the scan, such
+// as an unreadable folder or a file that vanished mid-walk
+type scanError struct {
+	path string
+	err  error
+}
+
+// updateErrors handles keys while the error log is open
+func (m model) updateErrors(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	page := max(m.height-4, 1)
+	last := max(len(m.scanErrors)-page, 0)
+	switch a := m.keys.action(msg); {
+	case a == actQuit:
+		return m, tea.Quit
+	case a == actErrors, msg.String() == "esc":
+		m.showErrors = false
+	case a == actUp:
+		m.errOffset--
+	case a == actDown:
+		m.errOffset++
+	case a == actPageUp:
+		m.errOffset -= page
+	case a == actPageDown:
+		m.errOffset += page
+	case a == actHome:
+		m.errOffset = 0
+	case a == actEnd:
+		m.errOffset = last
+	}
+	m.errOffset = max(min(m.errOffset, last), 0)
+	return m, nil
+}
+
+// errorsView renders the full-screen, scrollable list of scan errors
+func (m model) errorsView() string {
+	var s strings.Builder
+	s.WriteString(m.styles.title.Render(fmt.Sprintf(" Disk Usage Analyzer - ERRORS (%d) ", len(m.scanErrors))) + "\n\n")
+
+	if len(m.scanErrors) == 0 {
+		s.WriteString(m.styles.normal.Render("The scan completed without errors") + "\n")
+	}
+	page := max(m.height-4, 1)
+	end := min(m.errOffset+page, len(m.scanErrors))
+	for _, e := range m.scanErrors[m.errOffset:end] {
+		path := truncateFromStart(printable(getRelativePath(e.path, m.basePath)), m.width/2)
+		s.WriteString(m.styles.size.Render(path) + " " + m.styles.errorText.Render(printable(e.err.Error())) + "\n")
+	}
+
+	s.WriteString(m.styles.helpText.Render("\n" + m.keys.hints(
+		hint{"Scroll", []action{actUp, actDown}},
+		hint{"Close errors", []action{actErrors}},
+		hint{"Quit", []action{actQuit}},
+	)))
+	return s.String()
+}
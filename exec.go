@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// execDoneMsg reports the outcome of a command started with runExec
+type execDoneMsg struct {
+	name   string
+	output string
+	err    error
+}
+
+func (m execDoneMsg) String() string {
+	// Only the last line of output fits in the status line
+	out := strings.TrimSpace(m.output)
+	if i := strings.LastIndexByte(out, '\n'); i >= 0 {
+		out = out[i+1:]
+	}
+	switch {
+	case m.err != nil && out != "":
+		return fmt.Sprintf("%s: %v: %s", m.name, m.err, out)
+	case m.err != nil:
+		return fmt.Sprintf("%s: %v", m.name, m.err)
+	case out != "":
+		return fmt.Sprintf("%s: %s", m.name, out)
+	}
+	return m.name + ": done"
+}
+
+// splitArgs splits a command template into arguments on whitespace, keeping
+// single- or double-quoted sections together
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	var quote rune
+	inArg := false
+
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote in command")
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// commandArgs expands template for path, replacing {} in each argument. The
+// path is appended as the last argument if the template has no {}.
+//
+// The template is split before the path is substituted, so quotes, spaces or
+// shell metacharacters in a file name always stay inside a single argument.
+func commandArgs(template, path string) ([]string, error) {
+	path = safePath(path)
+	args, err := splitArgs(template)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, errors.New("empty command")
+	}
+
+	found := false
+	for i, arg := range args {
+		if strings.Contains(arg, "{}") {
+			args[i] = strings.ReplaceAll(arg, "{}", path)
+			found = true
+		}
+	}
+	if !found {
+		args = append(args, path)
+	}
+	return args, nil
+}
+
+// safePath makes sure path can't be mistaken for a command-line option
+func safePath(path string) string {
+	if strings.HasPrefix(path, "-") {
+		return "." + string(filepath.Separator) + path
+	}
+	return path
+}
+
+// runExec runs the command template on path and reports its output
+func runExec(template, path string) tea.Cmd {
+	return func() tea.Msg {
+		args, err := commandArgs(template, path)
+		if err != nil {
+			return execDoneMsg{name: "exec", err: err}
+		}
+		out, err := exec.Command(args[0], args[1:]...).CombinedOutput()
+		return execDoneMsg{name: args[0], output: string(out), err: err}
+	}
+}
+
+// openCommand returns the platform's "open with default application" command
+// for path. The path is always a separate argument; no shell is involved.
+func openCommand(path string) *exec.Cmd {
+	path = safePath(path)
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", path)
+	case "windows":
+		// "cmd /c start" would re-parse the path, so go through the shell API
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", path)
+	default:
+		return exec.Command("xdg-open", path)
+	}
+}
+
+// runOpen opens path with the default application without waiting for it
+func runOpen(path string) tea.Cmd {
+	return func() tea.Msg {
+		cmd := openCommand(path)
+		name := filepath.Base(cmd.Path)
+		if err := cmd.Start(); err != nil {
+			return execDoneMsg{name: name, err: err}
+		}
+		go cmd.Wait()
+		return execDoneMsg{name: name, output: "opened " + filepath.Base(path)}
+	}
+}
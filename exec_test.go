@@ -0,0 +1,59 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCommandArgs(t *testing.T) {
+	dashed := "." + string(filepath.Separator) + "-rf"
+	tests := []struct {
+		template, path string
+		want           []string
+	}{
+		{"less", "/tmp/a b", []string{"less", "/tmp/a b"}},
+		{"vim {}", "/tmp/a b", []string{"vim", "/tmp/a b"}},
+		{"cp {} {}.bak", "/tmp/x", []string{"cp", "/tmp/x", "/tmp/x.bak"}},
+		{`sh -c 'wc -c "$1"' sh {}`, "/tmp/$(rm -rf ~); `x`", []string{"sh", "-c", `wc -c "$1"`, "sh", "/tmp/$(rm -rf ~); `x`"}},
+		{`"my editor" --`, "it's", []string{"my editor", "--", "it's"}},
+		{"rm", "-rf", []string{"rm", dashed}},
+		{"ls -l {}", "-rf", []string{"ls", "-l", dashed}},
+	}
+	for _, tt := range tests {
+		got, err := commandArgs(tt.template, tt.path)
+		if err != nil {
+			t.Errorf("commandArgs(%q, %q): %v", tt.template, tt.path, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("commandArgs(%q, %q) = %q, want %q", tt.template, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCommandArgsErrors(t *testing.T) {
+	for _, template := range []string{"", "   ", `vim "{}`} {
+		if args, err := commandArgs(template, "/tmp/x"); err == nil {
+			t.Errorf("commandArgs(%q) = %q, want an error", template, args)
+		}
+	}
+}
+
+func TestSafePath(t *testing.T) {
+	sep := string(filepath.Separator)
+	tests := map[string]string{
+		"/tmp/-x":   "/tmp/-x",
+		"relative":  "relative",
+		"-n":        "." + sep + "-n",
+		"--help":    "." + sep + "--help",
+		"a -b":      "a -b",
+		"":          "",
+		sep + "-in": sep + "-in",
+	}
+	for path, want := range tests {
+		if got := safePath(path); got != want {
+			t.Errorf("safePath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
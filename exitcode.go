@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// Exit codes, so scripts and monitoring checks can tell outcomes apart.
+// Only -ndjson runs report the outcome of the scan itself; the TUI exits with
+// exitOK once it's started.
+const (
+	exitOK         = 0
+	exitError      = 1 // anything else, e.g. failing to write the output
+	exitUsage      = 2 // invalid flags or arguments
+	exitNotFound   = 3 // the path to scan doesn't exist
+	exitPermission = 4 // some entries couldn't be read for lack of permission
+	exitThreshold  = 5 // the scanned size is above -alert-above
+	exitNoMatch    = 6 // the scan listed no files, e.g. -since matched nothing
+)
+
+// errorExitCode maps a failure to start the scan to its exit code
+func errorExitCode(err error) int {
+	if errors.Is(err, fs.ErrNotExist) {
+		return exitNotFound
+	}
+	if errors.Is(err, fs.ErrPermission) {
+		return exitPermission
+	}
+	return exitError
+}
+
+// scanExitCode returns the exit code of a completed -ndjson scan. A size
+// above the threshold matters most to a monitoring check, so it wins over
+// the others. alertAbove is 0 when no threshold is set.
+func scanExitCode(res scanResult, alertAbove int64, allocated bool) int {
+	size := res.rootSize
+	if allocated {
+		size = res.rootAlloc
+	}
+	if alertAbove > 0 && size > alertAbove {
+		return exitThreshold
+	}
+	if len(res.files) == 0 {
+		return exitNoMatch
+	}
+	for _, e := range res.errors {
+		if errors.Is(e.err, fs.ErrPermission) {
+			return exitPermission
+		}
+	}
+	return exitOK
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/text/unicode/norm"
+)
+
+// rowView is the active list narrowed to the items matching the filter.
+// Rows point back into the list, so changes made through at() stick.
+type rowView struct {
+	items Items
+	idx   []int      // item index of each row, nil when every item is shown
+	rest  paretoRest // items collapsed by the Pareto view
+	loose *Item      // the combined view's first row, summing up the loose files; its index is -1
+}
+
+func (v rowView) len() int {
+	if v.idx == nil {
+		return len(v.items)
+	}
+	return len(v.idx)
+}
+
+// index returns the list index of the item shown on row
+func (v rowView) index(row int) int {
+	if v.idx == nil {
+		return row
+	}
+	return v.idx[row]
+}
+
+func (v rowView) at(row int) *Item {
+	if i := v.index(row); i >= 0 {
+		return &v.items[i]
+	}
+	return v.loose
+}
+
+// rowFor returns the row showing list index i, or the row of the next
+// surviving item after it when it's filtered out
+func (v rowView) rowFor(i int) int {
+	if v.idx == nil {
+		return i
+	}
+	return sort.SearchInts(v.idx, i)
+}
+
+// rows returns the visible rows of the active list
+func (m model) rows() rowView {
+	items := m.activeItems()
+	if m.filter == "" && m.scope == "" && m.viewMode != "combined" && !m.opts.shallow && !m.opts.pareto {
+		return rowView{items: items}
+	}
+	idx := []int{}
+	for i, item := range items {
+		if m.matches(item) {
+			idx = append(idx, i)
+		}
+	}
+	v := rowView{items: items, idx: idx}
+	var loose *Item
+	if m.viewMode == "combined" {
+		loose = m.looseRow(items, idx)
+	}
+	if m.opts.pareto {
+		v = m.pareto(v)
+	}
+	if loose != nil {
+		v.loose, v.idx = loose, append([]int{-1}, v.idx...)
+	}
+	return v
+}
+
+// matches reports whether item is inside the current folder, directly in it
+// in the combined and shallow views, and passes the filter. The root summary
+// row is never filtered out.
+func (m model) matches(item Item) bool {
+	if !m.inScope(item) || (m.viewMode == "combined" || m.opts.shallow) && !item.IsRoot && !m.topLevel(item) {
+		return false
+	}
+	return item.IsRoot || strings.Contains(m.foldFilter(getRelativePath(item.Path, m.dir())), m.foldFilter(m.filter))
+}
+
+// foldFilter normalizes s for filtering: NFC, so names stored decomposed (as
+// on macOS) match composed input, and lower case unless the filter is
+// case-sensitive
+func (m model) foldFilter(s string) string {
+	s = norm.NFC.String(s)
+	if !m.opts.caseSensitive {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+// filterSpans returns where the filter matches s, as byte ranges of s
+// itself. Matching is done on the folded text; each normalization segment
+// maps back to its original bytes, so a match is never split inside a
+// character.
+func (m model) filterSpans(s string) []span {
+	query := m.foldFilter(m.filter)
+	if query == "" {
+		return nil
+	}
+
+	// start and end give, for each byte of the folded text, the bounds of the
+	// segment of s it came from
+	var folded strings.Builder
+	var start, end []int
+	var it norm.Iter
+	it.InitString(norm.NFC, s)
+	for !it.Done() {
+		from := it.Pos()
+		seg := string(it.Next())
+		if !m.opts.caseSensitive {
+			seg = strings.ToLower(seg)
+		}
+		to := it.Pos()
+		folded.WriteString(seg)
+		for range len(seg) {
+			start, end = append(start, from), append(end, to)
+		}
+	}
+
+	var spans []span
+	text := folded.String()
+	for i := 0; i < len(text); {
+		j := strings.Index(text[i:], query)
+		if j < 0 {
+			break
+		}
+		from, to := i+j, i+j+len(query)
+		spans = append(spans, span{start[from], end[to-1]})
+		i = to
+	}
+	return spans
+}
+
+// setFilter changes the filter while keeping the cursor on the focused item,
+// or on its nearest surviving neighbour if the item no longer matches
+func (m model) setFilter(filter string) model {
+	focused := -1
+	if old := m.rows(); m.cursor >= 0 && m.cursor < old.len() {
+		focused = old.index(m.cursor)
+	}
+
+	m.filter = filter
+	if focused >= 0 {
+		next := m.rows()
+		m.cursor = min(next.rowFor(focused), next.len()-1)
+	}
+	return m.clampView()
+}
+
+// promptFilter opens the filter input, applying it live as it is typed
+func (m model) promptFilter() model {
+	previous := m.filter
+	m.prompt = &prompt{
+		label: "Filter: ",
+		input: m.filter,
+		onChange: func(m model, value string) model {
+			return m.setFilter(value)
+		},
+		onSubmit: func(m model, value string) (model, tea.Cmd) {
+			return m.setFilter(value), nil
+		},
+		onCancel: func(m model) model {
+			return m.setFilter(previous)
+		},
+	}
+	return m
+}
+
+// viewTotal sums the sizes of the visible rows. In the folders view a folder
+// inside another visible folder is already part of its size, so only the
+// outermost ones count.
+func (m model) viewTotal() int64 {
+	rows := m.rows()
+	visible := make(map[string]bool, rows.len())
+	for row := 0; row < rows.len(); row++ {
+		visible[rows.at(row).Path] = true
+	}
+
+	var total int64
+	for row := 0; row < rows.len(); row++ {
+		item := rows.at(row)
+		if item.IsRoot || item.IsLoose {
+			continue
+		}
+		if _, nested := m.visibleParent(item.Path, visible); nested && m.viewMode == "folders" {
+			continue
+		}
+		total += m.sizeOf(*item)
+	}
+	return total + rows.rest.size
+}
+
+// visibleParent returns the closest folder above path that is in visible,
+// looking no higher than the folder being browsed
+func (m model) visibleParent(path string, visible map[string]bool) (string, bool) {
+	for dir := filepath.Dir(path); dir != m.dir() && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if visible[dir] {
+			return dir, true
+		}
+	}
+	return "", false
+}
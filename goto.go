@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// promptGoto asks for a folder to rescan as the new base path. Relative
+// paths start from the folder being browsed, and Tab completes the last
+// path component.
+func (m model) promptGoto() model {
+	if m.opts.fromNDJSON {
+		m.status = "Going to a path needs a local scan"
+		return m
+	}
+	m.prompt = &prompt{
+		label: "Go to: ",
+		onSubmit: func(m model, value string) (model, tea.Cmd) {
+			if value == "" {
+				return m, nil
+			}
+			path := m.gotoPath(value)
+			info, err := os.Stat(path)
+			if err != nil {
+				m.status = "Can't go to " + value + ": " + err.Error()
+				return m, nil
+			}
+			if !info.IsDir() {
+				m.status = "Can't go to " + value + ": not a folder"
+				return m, nil
+			}
+			return m.scanPath(path)
+		},
+		complete: func(m model, value string) string {
+			return completeDir(m.gotoPath, value)
+		},
+	}
+	return m
+}
+
+// gotoPath resolves a path typed at the goto prompt: a leading ~ is the home
+// folder and a relative path starts from the folder being browsed
+func (m model) gotoPath(value string) string {
+	if value == "~" || strings.HasPrefix(value, "~"+string(filepath.Separator)) {
+		if home, err := os.UserHomeDir(); err == nil {
+			value = home + value[1:]
+		}
+	}
+	if !filepath.IsAbs(value) {
+		value = filepath.Join(m.dir(), value)
+	}
+	return value
+}
+
+// completeDir extends the last component of value to the longest prefix
+// shared by the folders it could name, adding a separator once only one is
+// left. Hidden folders are offered only when the component starts with a dot.
+func completeDir(resolve func(string) string, value string) string {
+	head, partial := "", value
+	if i := strings.LastIndexByte(value, filepath.Separator); i >= 0 {
+		head, partial = value[:i+1], value[i+1:]
+	}
+	dir := "."
+	if head != "" {
+		dir = head
+	}
+	entries, err := os.ReadDir(resolve(dir))
+	if err != nil {
+		return value
+	}
+	var matches []string
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, partial) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(partial, ".")) {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(resolve(dir), name)); err == nil && info.IsDir() {
+			matches = append(matches, name)
+		}
+	}
+	if len(matches) == 0 {
+		return value
+	}
+	if len(matches) == 1 {
+		return head + matches[0] + string(filepath.Separator)
+	}
+	common := matches[0]
+	for _, name := range matches[1:] {
+		for !strings.HasPrefix(name, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	return head + common
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// helpEntry describes the keys bound to actions, or fixed keys such as
+// mouse clicks when actions is empty
+type helpEntry struct {
+	actions []action
+	keys    string
+	desc    string
+}
+
+func bound(desc string, actions ...action) helpEntry {
+	return helpEntry{actions: actions, desc: desc}
+}
+
+func fixed(keys, desc string) helpEntry {
+	return helpEntry{keys: keys, desc: desc}
+}
+
+// helpLine is the short key reference below the list
+var helpLine = []hint{
+	{"Navigate", []action{actUp, actDown}},
+	{"Page", []action{actPageUp, actPageDown}},
+	{"Jump", []action{actHome, actEnd}},
+	{"Switch View", []action{actSwitchView}},
+	{"Open Folder", []action{actOpenFolder}},
+	{"Filter", []action{actFilter}},
+	{"Sort", []action{actSort}},
+	{"Info", []action{actInfo}},
+	{"Open", []action{actOpen}},
+	{"Exec", []action{actExec}},
+	{"Select", []action{actSelect}},
+	{"Delete", []action{actDelete}},
+	{"Help", []action{actHelp}},
+	{"Quit", []action{actQuit}},
+}
+
+type helpSection struct {
+	title   string
+	entries []helpEntry
+}
+
+// helpSections lists every key binding shown in the help overlay
+var helpSections = []helpSection{
+	{
+		title: "Navigation",
+		entries: []helpEntry{
+			bound("Move up", actUp),
+			bound("Move down", actDown),
+			bound("Scroll one page", actPageUp, actPageDown),
+			bound("Jump to first/last item", actHome, actEnd),
+			bound("Switch between the files, folders and combined views", actSwitchView),
+			bound("Toggle listing only the immediate children of this folder", actShallow),
+			bound("Toggle listing only the largest items that make up most of the total", actPareto),
+			bound("Open the folder under the cursor", actOpenFolder),
+			bound("Go back to the previous folder", actBack),
+			bound("Go back to the scanned directory", actTop),
+			bound("Jump to the folder holding the largest file of the scan", actJumpLargest),
+			bound("Pin or unpin the current folder as a bookmark", actBookmark),
+			bound("Rescan the next bookmarked folder", actNextBookmark),
+			bound("Go to another folder, rescanning it as the base path", actGoto),
+			bound("Scan the folder under the cursor in a second pane, or close the other pane", actSplit),
+			bound("Switch between the two panes", actSwitchPane),
+			bound("Leave the current item out of future scans, or take it off the ignore list", actIgnore),
+			bound("Filter by path, applied as you type", actFilter),
+			bound("Clear the filter", actClearFilter),
+			bound("Toggle case-sensitive filtering", actFilterCase),
+		},
+	},
+	{
+		title: "Display",
+		entries: []helpEntry{
+			bound("Toggle apparent/allocated sizes", actAllocated),
+			bound("Toggle folder sizes between recursive totals and visible subfolders", actVisibleSizes),
+			bound("Cycle size units: SI, IEC, exact bytes", actUnits),
+			bound("Toggle the exact BYTES column", actBytes),
+			bound("Toggle each item's share of the total", actPercent),
+			bound("Toggle the trailing / on folder names", actDirSlash),
+			bound("Toggle the folder item count column", actCounts),
+			bound("Toggle the folder average entry size column", actAvg),
+			bound("Toggle the inode column and volume inode usage", actInodes),
+			bound("Toggle the size distribution sparkline", actHistogram),
+			bound("Show a chart of this folder's immediate children by share", actPie),
+			bound("Toggle the DEPTH column", actDepth),
+			bound("Cycle focus mode: hide the status and key hints, then the title and header too", actFocus),
+			bound("Show details of the current item", actInfo),
+			bound("Show the exact byte counts of the current item in the status line", actExactSize),
+			bound("Compare sizes with the current item, shown as multiples of it; again to clear", actBaseline),
+			bound("Toggle the RATIO column of sizes relative to the baseline", actRatio),
+		},
+	},
+	{
+		title: "Sorting",
+		entries: []helpEntry{
+			bound("Cycle sort column", actSort),
+			bound("Reverse sort direction", actReverseSort),
+			fixed("Click header", "Sort by that column, click again to reverse"),
+		},
+	},
+	{
+		title: "Selection",
+		entries: []helpEntry{
+			bound("Toggle selection of the current item", actSelect),
+			bound("Keep the first N items by the current sort, select the rest", actKeep),
+		},
+	},
+	{
+		title: "Actions",
+		entries: []helpEntry{
+			bound("Review the selection by folder, then delete it", actDelete),
+			bound("Move the selection to another folder", actMove),
+			bound("Copy the selection to another folder", actCopy),
+			bound("Open the current item with the default application", actOpen),
+			bound("Run the -exec command (or a prompted one) on the current item", actExec),
+			bound("Confirm/cancel deletion or move", actConfirm, actCancel),
+			bound("Rescan/scan another path once the scanned volume is gone", actRescan, actPath),
+		},
+	},
+	{
+		title: "General",
+		entries: []helpEntry{
+			bound("Copy a command line that reproduces this view", actCopyCommand),
+			bound("Take a snapshot; again to see what changed since the first", actSnapshot),
+			bound("Checksum the selected files (Esc cancels)", actChecksum),
+			bound("Retry a deletion refused for lack of permission with sudo", actSudo),
+			bound("Show errors from scanning, moving and copying", actErrors),
+			bound("Toggle this help", actHelp),
+			fixed("Esc", "Close this help"),
+			bound("Quit", actQuit),
+			bound("Save a report of this view and the session's deletions, then quit", actQuitReport),
+		},
+	},
+}
+
+// entryKeys returns the keys shown for a help entry
+func (m model) entryKeys(e helpEntry) string {
+	if len(e.actions) == 0 {
+		return e.keys
+	}
+	return m.keys.help(e.actions...)
+}
+
+// helpView renders the full-screen key binding overlay
+func (m model) helpView() string {
+	keyWidth := 0
+	for _, section := range helpSections {
+		for _, e := range section.entries {
+			keyWidth = max(keyWidth, lipgloss.Width(m.entryKeys(e)))
+		}
+	}
+
+	keyStyle := m.styles.size.Width(keyWidth + 2)
+	sectionStyle := m.styles.header.Padding(0, 1)
+
+	var s strings.Builder
+	s.WriteString(m.styles.title.Render(" Disk Usage Analyzer - HELP ") + "\n")
+	for _, section := range helpSections {
+		s.WriteString("\n" + sectionStyle.Render(section.title) + "\n")
+		for _, e := range section.entries {
+			s.WriteString("  " + keyStyle.Render(m.entryKeys(e)) + m.styles.normal.Render(e.desc) + "\n")
+		}
+	}
+	s.WriteString(m.styles.helpText.Render("\n" + m.keys.short(actHelp) + "/Esc: Close help"))
+
+	// Pad lines to a common width so the block stays left-aligned when centered
+	block := lipgloss.JoinVertical(lipgloss.Left, strings.Split(s.String(), "\n")...)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, block)
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+import "os"
+
+// osHidden reports no hidden attribute where there is none; the leading dot
+// is the only convention
+func osHidden(info os.FileInfo) bool {
+	return false
+}
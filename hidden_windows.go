@@ -0,0 +1,16 @@
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// osHidden reports whether Explorer hides the entry by default: it has the
+// hidden or the system attribute, as pagefile.sys, desktop.ini and
+// "System Volume Information" do
+func osHidden(info os.FileInfo) bool {
+	if attrs, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return attrs.FileAttributes&(syscall.FILE_ATTRIBUTE_HIDDEN|syscall.FILE_ATTRIBUTE_SYSTEM) != 0
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// span is a byte range [start, end) of a string
+type span struct{ start, end int }
+
+// columnMatches finds where the filter matched an item's relative path and
+// splits the matches between the name and path columns. A match spanning
+// the separator is highlighted on both sides.
+func (m model) columnMatches(item Item) (name, path []span) {
+	if m.filter == "" || item.IsRoot || item.IsLoose {
+		return nil, nil
+	}
+	// Matched against the displayed text, so the spans line up with it
+	rel := printable(getRelativePath(item.Path, m.dir()))
+	nameStart := len(rel) - len(printable(filepath.Base(item.Path)))
+	dirEnd := nameStart - 1 // the path column shows "." for direct children
+
+	for _, sp := range m.filterSpans(rel) {
+		start, end := sp.start, sp.end
+		if end > nameStart {
+			name = append(name, span{max(start, nameStart) - nameStart, end - nameStart})
+		}
+		if start < dirEnd {
+			path = append(path, span{start, min(end, dirEnd)})
+		}
+	}
+	return name, path
+}
+
+// fitColumn truncates text to width like truncateString (or
+// truncateFromStart with keepEnd), highlighting matches. When the first
+// match would be cut off, the visible window moves to show it instead.
+// Lengths are measured in runes so multi-byte names are never split.
+func (m model) fitColumn(text string, matches []span, width int, keepEnd bool) string {
+	if width < 4 {
+		if keepEnd {
+			return truncateFromStart(text, width)
+		}
+		return truncateString(text, width)
+	}
+
+	runes := []rune(text)
+	n := len(runes)
+	for i, sp := range matches {
+		matches[i] = span{utf8.RuneCountInString(text[:sp.start]), utf8.RuneCountInString(text[:sp.end])}
+	}
+
+	start, end, pre, post := 0, n, "", ""
+	if n > width {
+		if keepEnd {
+			start, pre = n-(width-3), "..."
+		} else {
+			end, post = width-3, "..."
+		}
+		if len(matches) > 0 && width >= 7 {
+			first := matches[0]
+			switch {
+			case first.start >= start && first.end <= end:
+				// already visible
+			case first.end <= width-3:
+				start, end, pre, post = 0, width-3, "", "..."
+			case first.start >= n-(width-3):
+				start, end, pre, post = n-(width-3), n, "...", ""
+			default:
+				visible := width - 6
+				start = first.start - max(0, (visible-(first.end-first.start))/2)
+				start = max(0, min(start, n-visible))
+				end, pre, post = start+visible, "...", "..."
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(pre)
+	pos := start
+	for _, sp := range matches {
+		s, e := max(sp.start, pos), min(sp.end, end)
+		if s >= e {
+			continue
+		}
+		b.WriteString(string(runes[pos:s]))
+		b.WriteString(m.styles.match.Render(string(runes[s:e])))
+		pos = e
+	}
+	b.WriteString(string(runes[pos:end]))
+	b.WriteString(post)
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sparkBlocks are the bar heights of the size histogram, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// minSparkWidth is the narrowest histogram worth drawing
+const minSparkWidth = 8
+
+// sparkline summarizes the size distribution of the listed items as a
+// histogram on a log scale from the smallest to the largest size, one
+// bucket per screen column. Empty items fall in the first bucket.
+func (m model) sparkline() string {
+	rows := m.rows()
+	var sizes []int64
+	lo, hi := int64(0), int64(0)
+	for row := range rows.len() {
+		item := rows.at(row)
+		if item.IsRoot || item.IsLoose {
+			continue
+		}
+		size := m.sizeOf(*item)
+		sizes = append(sizes, size)
+		if size > 0 && (lo == 0 || size < lo) {
+			lo = size
+		}
+		if size > hi {
+			hi = size
+		}
+	}
+	if len(sizes) == 0 {
+		return ""
+	}
+
+	prefix := " Sizes " + m.formatSize(lo) + " "
+	suffix := " " + m.formatSize(hi) + " "
+	width := m.width - lipgloss.Width(prefix) - lipgloss.Width(suffix)
+	if width < minSparkWidth {
+		return ""
+	}
+
+	counts := make([]int, width)
+	span := 0.0
+	if lo > 0 {
+		span = math.Log(float64(hi) / float64(lo))
+	}
+	for _, size := range sizes {
+		bucket := 0
+		if size > 0 && span > 0 {
+			bucket = int(math.Log(float64(size)/float64(lo)) / span * float64(width))
+		}
+		counts[min(bucket, width-1)]++
+	}
+	peak := 0
+	for _, n := range counts {
+		peak = max(peak, n)
+	}
+
+	var bars strings.Builder
+	for _, n := range counts {
+		if n == 0 {
+			bars.WriteRune(' ')
+			continue
+		}
+		level := (n*len(sparkBlocks) - 1) / peak
+		bars.WriteRune(sparkBlocks[level])
+	}
+	return m.styles.helpText.Render(prefix) + m.styles.size.Render(bars.String()) + m.styles.helpText.Render(suffix)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// loadIgnore reads the ignore list, one pattern per line, skipping blank
+// lines and # comments. A missing or unreadable file means nothing is
+// ignored.
+func loadIgnore() []string {
+	file, err := configFile("ignore")
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// updateIgnore adds path to the end of the ignore list, or removes its line,
+// leaving everything else in the file, comments included, as it was
+func updateIgnore(path string, add bool) error {
+	file, err := configFile("ignore")
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var lines []string
+	if text := strings.TrimSuffix(string(data), "\n"); text != "" {
+		lines = strings.Split(text, "\n")
+	}
+	if add {
+		lines = append(lines, path)
+	} else {
+		lines = slices.DeleteFunc(lines, func(line string) bool { return strings.TrimSpace(line) == path })
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, []byte(strings.Join(lines, "\n")+"\n"), 0o644)
+}
+
+// ignoredBy reports whether path matches one of the patterns: a pattern
+// with a separator, such as /home/me/vms or /srv/*/cache, is matched against
+// the whole path, and one without, such as node_modules or *.iso, against
+// the name alone
+func ignoredBy(patterns []string, path string) bool {
+	for _, p := range patterns {
+		target := filepath.Base(path)
+		if strings.ContainsRune(p, filepath.Separator) {
+			target = path
+		}
+		if ok, _ := filepath.Match(p, target); ok || p == target {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleIgnore adds the item under the cursor to the ignore list, so the
+// next scan leaves it out, or takes it off again. It stays listed until
+// then, marked as ignored.
+func (m model) toggleIgnore() model {
+	if m.opts.fromNDJSON {
+		m.status = "The ignore list needs a local scan"
+		return m
+	}
+	item, ok := m.currentItem()
+	if !ok || item.IsRoot {
+		m.status = "The scanned folder itself can't be ignored"
+		return m
+	}
+	name := filepath.Base(item.Path)
+	i := slices.Index(m.ignore, item.Path)
+	if err := updateIgnore(item.Path, i < 0); err != nil {
+		m.status = "Can't save the ignore list: " + err.Error()
+		return m
+	}
+	if i >= 0 {
+		m.ignore = slices.Delete(slices.Clone(m.ignore), i, i+1)
+		m.status = "No longer ignoring " + name
+	} else {
+		m.ignore = append(slices.Clone(m.ignore), item.Path)
+		m.status = "Ignoring " + name + " from the next scan - press " + m.keys.short(actIgnore) + " again to undo"
+	}
+	if !m.opts.noIgnore {
+		m.opts.scan.ignore = m.ignore
+	}
+	return m
+}
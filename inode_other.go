@@ -0,0 +1,22 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// inodeID identifies a file's inode across hard links
+type inodeID struct {
+	dev, ino uint64
+}
+
+// deviceOf reports no device where it isn't available, so no folder is
+// flagged as a mount point
+func deviceOf(info os.FileInfo) (uint64, bool) {
+	return 0, false
+}
+
+// sharedInode reports no hard links where inode numbers aren't available, so
+// every entry counts as one inode
+func sharedInode(info os.FileInfo) (inodeID, bool) {
+	return inodeID{}, false
+}
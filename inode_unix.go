@@ -0,0 +1,32 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// inodeID identifies a file's inode across hard links
+type inodeID struct {
+	dev, ino uint64
+}
+
+// deviceOf returns the device the entry lives on
+func deviceOf(info os.FileInfo) (uint64, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(st.Dev), true
+}
+
+// sharedInode returns the inode of a file that has more than one hard link,
+// so it can be counted once however many names point at it
+func sharedInode(info os.FileInfo) (inodeID, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || info.IsDir() || st.Nlink < 2 {
+		return inodeID{}, false
+	}
+	return inodeID{dev: uint64(st.Dev), ino: uint64(st.Ino)}, true
+}
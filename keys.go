@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// action is something a key can be bound to. Its name is used in the key
+// config file.
+type action string
+
+const (
+	actQuit         action = "quit"
+	actQuitReport   action = "quit-report"
+	actHelp         action = "help"
+	actErrors       action = "errors"
+	actUp           action = "up"
+	actDown         action = "down"
+	actPageUp       action = "page-up"
+	actPageDown     action = "page-down"
+	actHome         action = "home"
+	actEnd          action = "end"
+	actSwitchView   action = "switch-view"
+	actOpenFolder   action = "open-folder"
+	actBack         action = "back"
+	actTop          action = "top"
+	actJumpLargest  action = "jump-largest"
+	actBookmark     action = "bookmark"
+	actNextBookmark action = "next-bookmark"
+	actGoto         action = "goto"
+	actSplit        action = "split"
+	actSwitchPane   action = "switch-pane"
+	actIgnore       action = "ignore"
+	actFilter       action = "filter"
+	actClearFilter  action = "clear-filter"
+	actFilterCase   action = "filter-case"
+	actAllocated    action = "allocated"
+	actVisibleSizes action = "visible-sizes"
+	actUnits        action = "units"
+	actBytes        action = "bytes"
+	actPercent      action = "percent"
+	actDirSlash     action = "dir-slash"
+	actCounts       action = "counts"
+	actAvg          action = "avg"
+	actInodes       action = "inodes"
+	actHistogram    action = "histogram"
+	actPie          action = "pie"
+	actFocus        action = "focus"
+	actShallow      action = "shallow"
+	actPareto       action = "pareto"
+	actDepth        action = "depth"
+	actInfo         action = "info"
+	actExactSize    action = "exact-size"
+	actBaseline     action = "baseline"
+	actRatio        action = "ratio"
+	actSort         action = "sort"
+	actReverseSort  action = "reverse-sort"
+	actSelect       action = "select"
+	actKeep         action = "keep"
+	actDelete       action = "delete"
+	actMove         action = "move"
+	actCopy         action = "copy"
+	actOpen         action = "open"
+	actExec         action = "exec"
+	actConfirm      action = "confirm"
+	actCancel       action = "cancel"
+	actRescan       action = "rescan"
+	actPath         action = "scan-path"
+	actCopyCommand  action = "copy-command"
+	actSnapshot     action = "snapshot"
+	actChecksum     action = "checksum"
+	actSudo         action = "sudo"
+)
+
+// defaultBindings are the keys used when the config doesn't say otherwise.
+// The first key of each action is the one shown in the help line.
+var defaultBindings = map[action][]string{
+	actQuit:         {"q", "ctrl+c"},
+	actQuitReport:   {"Q"},
+	actHelp:         {"?"},
+	actErrors:       {"E"},
+	actUp:           {"up", "k"},
+	actDown:         {"down", "j"},
+	actPageUp:       {"pageup"},
+	actPageDown:     {"pagedown"},
+	actHome:         {"home"},
+	actEnd:          {"end"},
+	actSwitchView:   {"tab"},
+	actOpenFolder:   {"enter"},
+	actBack:         {"backspace"},
+	actTop:          {"~"},
+	actJumpLargest:  {"J"},
+	actBookmark:     {"m"},
+	actNextBookmark: {"'"},
+	actGoto:         {"g", ":"},
+	actSplit:        {"|"},
+	actSwitchPane:   {"w"},
+	actIgnore:       {"X"},
+	actFilter:       {"/"},
+	actClearFilter:  {"esc"},
+	actFilterCase:   {"F"},
+	actAllocated:    {"A"},
+	actVisibleSizes: {"T"},
+	actUnits:        {"u"},
+	actBytes:        {"b"},
+	actPercent:      {"%"},
+	actDirSlash:     {"\\"},
+	actCounts:       {"c"},
+	actAvg:          {"a"},
+	actInodes:       {"I"},
+	actHistogram:    {"H"},
+	actPie:          {"p"},
+	actFocus:        {"z"},
+	actShallow:      {"D"},
+	actPareto:       {"P"},
+	actDepth:        {"L"},
+	actInfo:         {"i"},
+	actExactSize:    {"="},
+	actBaseline:     {"B"},
+	actRatio:        {"R"},
+	actSort:         {"s"},
+	actReverseSort:  {"S"},
+	actSelect:       {" "},
+	actKeep:         {"K"},
+	actDelete:       {"d"},
+	actMove:         {"M"},
+	actCopy:         {"C"},
+	actOpen:         {"o"},
+	actExec:         {"x"},
+	actConfirm:      {"y"},
+	actCancel:       {"n"},
+	actRescan:       {"r"},
+	actPath:         {"p"},
+	actCopyCommand:  {"Y"},
+	actSnapshot:     {"["},
+	actChecksum:     {"#"},
+	actSudo:         {"!"},
+}
+
+// keymap resolves key presses to actions
+type keymap struct {
+	keys  map[action][]string
+	byKey map[string]action
+}
+
+// newKeymap builds a keymap, refusing bindings where one key would trigger
+// two actions. Confirm and cancel only apply while confirming a delete, and
+// rescan and scan-path only once the scanned volume is gone, so they may
+// reuse keys from the main view as long as they don't clash with each
+// other or, in the gone view, with quit.
+func newKeymap(bindings map[action][]string) (keymap, error) {
+	k := keymap{keys: bindings, byKey: map[string]action{}}
+	overlayOnly := func(a action) bool { return a == actConfirm || a == actCancel || a == actRescan || a == actPath }
+
+	names := make([]string, 0, len(bindings))
+	for a := range bindings {
+		names = append(names, string(a))
+	}
+	slices.Sort(names) // deterministic errors
+
+	for _, name := range names {
+		a := action(name)
+		if overlayOnly(a) {
+			continue
+		}
+		for _, key := range bindings[a] {
+			if other, taken := k.byKey[key]; taken {
+				return keymap{}, fmt.Errorf("%s is bound to both %s and %s", keyName(key), other, a)
+			}
+			k.byKey[key] = a
+		}
+	}
+	for _, pair := range [][2]action{{actConfirm, actCancel}, {actRescan, actPath}, {actRescan, actQuit}, {actPath, actQuit}} {
+		for _, key := range bindings[pair[0]] {
+			if slices.Contains(bindings[pair[1]], key) {
+				return keymap{}, fmt.Errorf("%s is bound to both %s and %s", keyName(key), pair[0], pair[1])
+			}
+		}
+	}
+	return k, nil
+}
+
+func defaultKeymap() keymap {
+	k, _ := newKeymap(defaultBindings)
+	return k
+}
+
+// action returns what msg is bound to in the main view, or ""
+func (k keymap) action(msg tea.KeyMsg) action {
+	return k.byKey[msg.String()]
+}
+
+// is reports whether msg is bound to a, for overlays with their own keys
+func (k keymap) is(msg tea.KeyMsg, a action) bool {
+	return slices.Contains(k.keys[a], msg.String())
+}
+
+// help lists every key bound to the actions, such as "↑/k"
+func (k keymap) help(actions ...action) string {
+	var names []string
+	for _, a := range actions {
+		for _, key := range k.keys[a] {
+			names = append(names, keyName(key))
+		}
+	}
+	return strings.Join(names, "/")
+}
+
+// short names the first key of each action, such as "↑/↓"
+func (k keymap) short(actions ...action) string {
+	var names []string
+	for _, a := range actions {
+		if keys := k.keys[a]; len(keys) > 0 {
+			names = append(names, keyName(keys[0]))
+		}
+	}
+	return strings.Join(names, "/")
+}
+
+// hint is one "keys: label" part of a help line
+type hint struct {
+	label   string
+	actions []action
+}
+
+// hints renders a help line such as "Tab: Switch View • q: Quit"
+func (k keymap) hints(hs ...hint) string {
+	parts := make([]string, len(hs))
+	for i, h := range hs {
+		parts[i] = k.short(h.actions...) + ": " + h.label
+	}
+	return strings.Join(parts, " • ")
+}
+
+var keyNames = map[string]string{
+	"up":        "↑",
+	"down":      "↓",
+	"left":      "←",
+	"right":     "→",
+	"pageup":    "PgUp",
+	"pagedown":  "PgDn",
+	"home":      "Home",
+	"end":       "End",
+	"tab":       "Tab",
+	"enter":     "Enter",
+	"backspace": "Backspace",
+	"esc":       "Esc",
+	" ":         "Space",
+}
+
+// keyName returns how a key is shown in help text
+func keyName(key string) string {
+	if name, ok := keyNames[key]; ok {
+		return name
+	}
+	if rest, ok := strings.CutPrefix(key, "ctrl+"); ok {
+		return "Ctrl+" + strings.ToUpper(rest)
+	}
+	return key
+}
+
+// loadKeymap applies the user's key config over the defaults. Each line of
+// the file rebinds one action:
+//
+//	down = j, down
+//	delete = D
+//
+// Keys use bubbletea's names ("ctrl+d", "pagedown"), with "space" and
+// "comma" for those two keys. A missing file means the defaults.
+func loadKeymap() (keymap, error) {
+	file, err := configFile("keys")
+	if err != nil {
+		return defaultKeymap(), nil
+	}
+	f, err := os.Open(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return defaultKeymap(), nil
+	}
+	if err != nil {
+		return keymap{}, err
+	}
+	defer f.Close()
+
+	bindings := make(map[action][]string, len(defaultBindings))
+	for a, keys := range defaultBindings {
+		bindings[a] = keys
+	}
+
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, list, ok := strings.Cut(text, "=")
+		a := action(strings.TrimSpace(name))
+		if _, known := defaultBindings[a]; !ok || !known {
+			return keymap{}, fmt.Errorf("%s:%d: expected action = keys, with a known action", file, line)
+		}
+		var keys []string
+		for _, key := range strings.Split(list, ",") {
+			key = strings.TrimSpace(key)
+			switch key {
+			case "space":
+				key = " "
+			case "comma":
+				key = ","
+			}
+			if key != "" {
+				keys = append(keys, key)
+			}
+		}
+		bindings[a] = keys
+	}
+	if err := sc.Err(); err != nil {
+		return keymap{}, err
+	}
+	return newKeymap(bindings)
+}
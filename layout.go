@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The main view is split into three regions. The top holds the title, the
+// full-disk banner when there is one, a summary line (the size histogram, or
+// blank) and the column header; the
+// bottom holds the status line and the help line. Both are rendered first
+// and the list in the middle gets exactly the height left over, so the
+// header never scrolls away and the status and help stay pinned at the
+// bottom however much is listed. New fixed lines belong in one of the outer
+// regions, where the list makes room for them.
+
+// focusLevel is how much of the screen furniture focus mode hides, leaving
+// more of the screen to the list for screenshots and presentations
+type focusLevel int
+
+const (
+	focusOff  focusLevel = iota
+	focusList            // no status line or key hints
+	focusBare            // the list alone, without the title, summary and header either
+	focusLevels
+)
+
+// titleText is the title bar, with the counts and every active mode
+func (m model) titleText(rows rowView) string {
+	title := fmt.Sprintf(" Disk Usage Analyzer - %s (%d/%d) %s - %s total ",
+		strings.ToUpper(m.viewMode),
+		min(m.cursor+1, max(rows.len(), 1)),
+		rows.len(),
+		m.formatSize(m.viewTotal()),
+		m.formatSize(m.grandTotal()),
+	)
+	if m.scanning != nil {
+		title += m.scanTitle()
+	}
+	if m.sizing != nil {
+		title += "- calculating folder sizes "
+	}
+	if m.mount != nil {
+		title += m.mountTitle()
+	}
+	if m.opts.quotaBytes > 0 {
+		title += m.quotaTitle()
+	}
+	if m.opts.inodes {
+		title += m.inodeTitle()
+	}
+	if m.opts.depth {
+		title += fmt.Sprintf("- max depth %d ", m.maxDepth())
+	}
+	if m.opts.scan.compressed && m.opts.allocated {
+		title += "- allocated sizes, compressed files marked "
+	}
+	title += m.symlinkTitle()
+	if m.opts.since != "" {
+		title += fmt.Sprintf("- since %s ", m.opts.since)
+	}
+	if m.opts.until != "" {
+		title += fmt.Sprintf("- until %s ", m.opts.until)
+	}
+	if m.scope != "" {
+		in := getRelativePath(m.scope, m.basePath)
+		if m.opts.typedRoot != "" {
+			in = m.displayPath(m.scope)
+		}
+		title += fmt.Sprintf("- in %s ", in)
+	}
+	if m.filter != "" {
+		title += fmt.Sprintf("- filter %q ", m.filter)
+		if m.opts.caseSensitive {
+			title += "(match case) "
+		}
+	}
+	if m.opts.shallow && m.viewMode != "combined" {
+		title += "- shallow "
+	}
+	if m.opts.pareto {
+		title += fmt.Sprintf("- top %d%% ", m.opts.paretoPercent)
+	}
+	if m.visibleSizes && m.viewMode == "folders" {
+		title += "- visible sizes "
+	}
+	if m.opts.fromNDJSON {
+		title += "[IMPORTED] "
+	}
+	if n := len(m.scanErrors); n == 1 {
+		title += "[1 ERROR - E] "
+	} else if n > 1 {
+		title += fmt.Sprintf("[%d ERRORS - E] ", n)
+	}
+	if m.opts.readonly {
+		title += "[READ-ONLY] "
+	} else if m.readOnlyFS {
+		title += "[READ-ONLY MOUNT] "
+	}
+	return title
+}
+
+// topRegion renders the title, summary and column header, each cut to the
+// screen width so none of them wraps
+func (m model) topRegion(rows rowView, cols columns) []string {
+	if m.focus == focusBare {
+		return nil
+	}
+	fit := lipgloss.NewStyle().MaxWidth(m.width)
+	summary := ""
+	if m.opts.histogram {
+		summary = m.sparkline()
+	}
+	top := []string{fit.Render(m.styles.title.Render(printable(m.titleText(rows))))}
+	if banner := m.fullBanner(); banner != "" {
+		top = append(top, fit.Render(m.styles.banner.Width(m.width).Render(banner)))
+	}
+	return append(top,
+		fit.Render(summary),
+		fit.Render(m.styles.header.Render("[ ] "+m.headerLine(cols))),
+	)
+}
+
+// headerRow is the screen line holding the column header, the last of the
+// top region; -1 when focus mode hides it
+func (m model) headerRow() int {
+	return len(m.topRegion(m.rows(), m.columns())) - 1
+}
+
+// bottomRegion renders the status line, blank when there's nothing to
+// report, and the key hints wrapped to the screen width. Focus mode hides
+// both, except for a prompt or question waiting for an answer.
+func (m model) bottomRegion(hints []hint) []string {
+	if m.focus != focusOff {
+		switch {
+		case m.prompt != nil:
+			return []string{m.prompt.view(m.styles)}
+		case m.sudo != nil && m.sudo.asking:
+			return []string{m.sudoQuestion()}
+		}
+		return nil
+	}
+	status := ""
+	switch {
+	case m.prompt != nil:
+		status = m.prompt.view(m.styles)
+	case m.sudo != nil && m.sudo.asking:
+		status = m.sudoQuestion()
+	case m.progress != nil:
+		status = m.progressView("Copying", m.progress.done, m.progress.total)
+	case m.deleting != nil:
+		status = m.progressView("Deleting", m.deleting.done, m.deleting.total)
+	case m.hashing != nil:
+		status = m.progressView("Checksumming", m.hashing.done, m.hashing.total)
+	case m.status != "":
+		status = m.styles.helpText.Render(printable(m.status))
+	default:
+		if peek := m.largestPeek(); peek != "" {
+			status = m.styles.helpText.Render(printable(peek))
+		}
+	}
+	help := m.styles.helpText.Width(max(m.width, 1)).Render(m.keys.hints(hints...))
+	return append([]string{status}, strings.Split(help, "\n")...)
+}
+
+// pageSize is how many list rows fit between the top and bottom regions
+func (m model) pageSize() int {
+	rows := m.rows()
+	return m.listHeight(rows, m.topRegion(rows, m.columns()), m.bottomRegion(helpLine))
+}
+
+// listHeight is the number of items shown at once, leaving room for the
+// row summing up what the Pareto view collapsed. -page-size caps it below
+// what the terminal could fit.
+func (m model) listHeight(rows rowView, top, bottom []string) int {
+	h := m.height - len(top) - len(bottom)
+	if rows.rest.count > 0 {
+		h--
+	}
+	if m.opts.pageSize > 0 {
+		h = min(h, m.opts.pageSize)
+	}
+	return max(h, 1)
+}
+
+// compose stacks the regions. On the alternate screen the middle is padded
+// or cut to the height left over, pinning the bottom region to the last
+// lines; inline, short lists aren't padded.
+func (m model) compose(top, middle, bottom []string) string {
+	if !m.opts.inline {
+		height := max(m.height-len(top)-len(bottom), 0)
+		if len(middle) > height {
+			middle = middle[:height]
+		}
+		for len(middle) < height {
+			middle = append(middle, "")
+		}
+	}
+	lines := append(append(append([]string{}, top...), middle...), bottom...)
+	return strings.Join(lines, "\n")
+}
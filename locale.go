@@ -0,0 +1,138 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+// locale adapts humanize's English output for sizes and relative times
+type locale struct {
+	name      string
+	decimal   string // decimal separator, "." in humanize output
+	thousands string // digit group separator, "," in humanize output
+	byteUnit  string // replaces the "B" unit suffix, e.g. "o" in French
+
+	ago, fromNow string
+	magnitudes   []humanize.RelTimeMagnitude // nil uses humanize's English table
+}
+
+// relMagnitudes pairs translated formats with humanize's default thresholds:
+// now, 1 second, seconds, 1 minute, minutes, 1 hour, hours, 1 day, days,
+// 1 week, weeks, 1 month, months, 1 year, 2 years, years, a long while
+func relMagnitudes(f [17]string) []humanize.RelTimeMagnitude {
+	return []humanize.RelTimeMagnitude{
+		{D: time.Second, Format: f[0], DivBy: time.Second},
+		{D: 2 * time.Second, Format: f[1], DivBy: 1},
+		{D: time.Minute, Format: f[2], DivBy: time.Second},
+		{D: 2 * time.Minute, Format: f[3], DivBy: 1},
+		{D: time.Hour, Format: f[4], DivBy: time.Minute},
+		{D: 2 * time.Hour, Format: f[5], DivBy: 1},
+		{D: humanize.Day, Format: f[6], DivBy: time.Hour},
+		{D: 2 * humanize.Day, Format: f[7], DivBy: 1},
+		{D: humanize.Week, Format: f[8], DivBy: humanize.Day},
+		{D: 2 * humanize.Week, Format: f[9], DivBy: 1},
+		{D: humanize.Month, Format: f[10], DivBy: humanize.Week},
+		{D: 2 * humanize.Month, Format: f[11], DivBy: 1},
+		{D: humanize.Year, Format: f[12], DivBy: humanize.Month},
+		{D: 18 * humanize.Month, Format: f[13], DivBy: 1},
+		{D: 2 * humanize.Year, Format: f[14], DivBy: 1},
+		{D: humanize.LongTime, Format: f[15], DivBy: humanize.Year},
+		{D: math.MaxInt64, Format: f[16], DivBy: 1},
+	}
+}
+
+var english = locale{name: "en", decimal: ".", thousands: ",", byteUnit: "B", ago: "ago", fromNow: "from now"}
+
+// locales holds the supported languages. Only languages that put the
+// "ago"/"from now" label on the same side of the number in both directions
+// fit humanize's single format string per magnitude.
+var locales = map[string]locale{
+	"en": english,
+	"de": {
+		name: "de", decimal: ",", thousands: ".", byteUnit: "B", ago: "vor", fromNow: "in",
+		magnitudes: relMagnitudes([17]string{
+			"jetzt", "%s 1 Sekunde", "%s %d Sekunden", "%s 1 Minute", "%s %d Minuten",
+			"%s 1 Stunde", "%s %d Stunden", "%s 1 Tag", "%s %d Tagen", "%s 1 Woche",
+			"%s %d Wochen", "%s 1 Monat", "%s %d Monaten", "%s 1 Jahr", "%s 2 Jahren",
+			"%s %d Jahren", "%s langer Zeit",
+		}),
+	},
+	"fr": {
+		name: "fr", decimal: ",", thousands: " ", byteUnit: "o", ago: "il y a", fromNow: "dans",
+		magnitudes: relMagnitudes([17]string{
+			"maintenant", "%s 1 seconde", "%s %d secondes", "%s 1 minute", "%s %d minutes",
+			"%s 1 heure", "%s %d heures", "%s 1 jour", "%s %d jours", "%s 1 semaine",
+			"%s %d semaines", "%s 1 mois", "%s %d mois", "%s 1 an", "%s 2 ans",
+			"%s %d ans", "%s très longtemps",
+		}),
+	},
+	"es": {
+		name: "es", decimal: ",", thousands: ".", byteUnit: "B", ago: "hace", fromNow: "dentro de",
+		magnitudes: relMagnitudes([17]string{
+			"ahora", "%s 1 segundo", "%s %d segundos", "%s 1 minuto", "%s %d minutos",
+			"%s 1 hora", "%s %d horas", "%s 1 día", "%s %d días", "%s 1 semana",
+			"%s %d semanas", "%s 1 mes", "%s %d meses", "%s 1 año", "%s 2 años",
+			"%s %d años", "%s mucho tiempo",
+		}),
+	},
+	"pt": {
+		name: "pt", decimal: ",", thousands: ".", byteUnit: "B", ago: "há", fromNow: "em",
+		magnitudes: relMagnitudes([17]string{
+			"agora", "%s 1 segundo", "%s %d segundos", "%s 1 minuto", "%s %d minutos",
+			"%s 1 hora", "%s %d horas", "%s 1 dia", "%s %d dias", "%s 1 semana",
+			"%s %d semanas", "%s 1 mês", "%s %d meses", "%s 1 ano", "%s 2 anos",
+			"%s %d anos", "%s muito tempo",
+		}),
+	},
+}
+
+// lookupLocale finds the locale for a tag such as "de", "de-AT" or
+// "de_DE.UTF-8", falling back to English when it isn't supported
+func lookupLocale(tag string) (locale, bool) {
+	lang := strings.ToLower(tag)
+	if i := strings.IndexAny(lang, "_-."); i >= 0 {
+		lang = lang[:i]
+	}
+	if lang == "" {
+		return english, true
+	}
+	l, ok := locales[lang]
+	if !ok {
+		return english, false
+	}
+	return l, true
+}
+
+// bytes formats a size like humanize.Bytes, e.g. "1,2 Go" in French
+func (l locale) bytes(n uint64) string {
+	return l.localize(humanize.Bytes(n))
+}
+
+// ibytes formats a size like humanize.IBytes, e.g. "1,2 Gio" in French
+func (l locale) ibytes(n uint64) string {
+	return l.localize(humanize.IBytes(n))
+}
+
+// localize swaps the decimal separator and byte unit of a humanized size
+func (l locale) localize(s string) string {
+	if l.byteUnit != "B" {
+		s = strings.TrimSuffix(s, "B") + l.byteUnit
+	}
+	return strings.Replace(s, ".", l.decimal, 1)
+}
+
+// comma formats an exact byte count with digit grouping
+func (l locale) comma(n int64) string {
+	return strings.ReplaceAll(humanize.Comma(n), ",", l.thousands)
+}
+
+// relTime formats t relative to now, e.g. "vor 3 Tagen"
+func (l locale) relTime(t time.Time) string {
+	if l.magnitudes == nil {
+		return humanize.Time(t)
+	}
+	return humanize.CustomRelTime(t, time.Now(), l.ago, l.fromNow, l.magnitudes)
+}
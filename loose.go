@@ -0,0 +1,77 @@
+package main
+
+import "fmt"
+
+// looseName labels the row summing up the files directly in the folder
+// being browsed
+const looseName = "(files in this directory)"
+
+// looseRow sums up the files among the rows at idx into the row that leads
+// the combined view, telling what lies loose in the folder apart from what
+// its subfolders hold. It's nil when there are no such files. The row
+// stands for no single entry on disk, so it takes the folder's path only to
+// place it; selecting it selects the files.
+func (m model) looseRow(items Items, idx []int) *Item {
+	loose := Item{Path: m.dir(), IsLoose: true}
+	for _, i := range idx {
+		if item := items[i]; !item.IsDir && !item.IsRoot {
+			loose.Size += item.Size
+			loose.Allocated += item.Allocated
+			loose.ItemCount++
+		}
+	}
+	if loose.ItemCount == 0 {
+		return nil
+	}
+	return &loose
+}
+
+// looseFiles returns the files the loose row sums up, including any the
+// Pareto view collapsed
+func (m model) looseFiles() Items {
+	var out Items
+	for _, item := range m.activeItems() {
+		if !item.IsDir && item.deletable() && m.matches(item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// looseSelected reports whether every file the loose row sums up is selected
+func (m model) looseSelected() bool {
+	files := m.looseFiles()
+	for _, item := range files {
+		if !m.selected[item.Path] {
+			return false
+		}
+	}
+	return len(files) > 0
+}
+
+// selectLoose selects or deselects all the loose files at once, ready to be
+// deleted together
+func (m model) selectLoose(on bool) model {
+	files := m.looseFiles()
+	var size int64
+	for _, item := range files {
+		m = m.setSelected(item.Path, on)
+		size += m.sizeOf(item)
+	}
+	noun := "files"
+	if len(files) == 1 {
+		noun = "file"
+	}
+	if on {
+		m.status = fmt.Sprintf("Selected %d loose %s in this folder (%s) - press %s to delete", len(files), noun, m.formatSize(size), m.keys.short(actDelete))
+	} else {
+		m.status = fmt.Sprintf("Deselected %d loose %s in this folder", len(files), noun)
+	}
+	return m
+}
+
+// looseActions are the item actions that make sense on the loose row; the
+// others would act on the folder whose path it borrows
+var looseActions = map[action]bool{
+	actSelect: true, actKeep: true, actInfo: true, actExactSize: true,
+}
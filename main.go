@@ -1,14 +1,18 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
 	"github.com/dustin/go-humanize"
 )
 
@@ -28,18 +32,54 @@ func max(a, b int) int {
 }
 
 func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	r := []rune(s)
+	switch {
+	case len(r) <= maxLen:
 		return s
+	case maxLen <= 0:
+		return ""
+	case maxLen < 4:
+		return "…"
 	}
-	return s[:maxLen-3] + "..."
+	return string(r[:maxLen-3]) + "..."
 }
 
 // truncateFromStart truncates a string from the beginning, keeping the end visible
 func truncateFromStart(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	r := []rune(s)
+	switch {
+	case len(r) <= maxLen:
 		return s
+	case maxLen <= 0:
+		return ""
+	case maxLen < 4:
+		return "…"
 	}
-	return "..." + s[len(s)-(maxLen-3):]
+	return "..." + string(r[len(r)-(maxLen-3):])
+}
+
+// padLeft right-aligns s in width terminal cells. Unlike %*s it measures
+// what is visible, so escape codes and multi-byte characters such as the
+// sort arrows don't throw columns off; style after padding or pass styled
+// text, either lines up.
+func padLeft(s string, width int) string {
+	return strings.Repeat(" ", max(width-lipgloss.Width(s), 0)) + s
+}
+
+// padRight left-aligns s in width terminal cells, like padLeft
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-lipgloss.Width(s), 0))
+}
+
+// displayPath returns a folder as shown in the PATH column: relative to the
+// folder being browsed, or with -as-typed, joined to the scan root as it was
+// typed, so "diskusage -as-typed ../logs" lists "../logs/app". Only the
+// display changes; every operation uses the absolute path.
+func (m model) displayPath(dir string) string {
+	if m.opts.typedRoot == "" {
+		return getRelativePath(dir, m.dir())
+	}
+	return filepath.Join(m.opts.typedRoot, getRelativePath(dir, m.basePath))
 }
 
 // getRelativePath returns path relative to basePath
@@ -52,9 +92,39 @@ func getRelativePath(fullPath, basePath string) string {
 }
 
 type Item struct {
-	Path       string
-	Size       int64
-	IsSelected bool
+	Path         string
+	Size         int64 // apparent size
+	Allocated    int64 // bytes actually allocated on disk
+	ModTime      time.Time
+	Created      time.Time  // birth time, only looked up with -created; zero if unknown
+	ItemCount    int        // files and subfolders contained in a folder, at any depth
+	Inodes       int        // inodes used by a folder and its contents
+	IsRoot       bool       // synthetic summary row for the scan root; never deletable
+	IsDir        bool       // a folder rather than a file
+	IsMount      bool       // a folder on a different device than its parent
+	IsLink       bool       // a symbolic link, listed as a file; never followed
+	IsLoose      bool       // synthetic row summing up the files directly in the folder being browsed
+	Compressed   bool       // a file the filesystem stores compressed, looked up with -compressed
+	LinkTarget   bool       // a link sized as the file it points to, with -symlinks target
+	SizePartial  bool       // parts of the folder couldn't be read, so it's bigger than shown
+	SizePending  bool       // the folder is still being sized in the background; its size is unknown
+	LargestChild *Item      // biggest file anywhere below a folder, found while sizing it
+	Delta        int64      // size change since the compared snapshot
+	Change       changeKind // whether the item was added or removed since the snapshot
+}
+
+// sparseMinSize is the smallest apparent-vs-allocated gap worth flagging
+const sparseMinSize = 1 << 20
+
+// isSparse reports whether far less space is allocated than the apparent size
+// suggests, as with VM images or preallocated database files
+func (i Item) isSparse() bool {
+	return i.Size-i.Allocated >= sparseMinSize && i.Allocated < i.Size/2
+}
+
+// deletable reports whether the item refers to something that can be removed
+func (i Item) deletable() bool {
+	return !i.IsRoot && !i.IsLoose && i.Change != changeRemoved
 }
 
 type Items []Item
@@ -64,18 +134,134 @@ func (i Items) Less(j, k int) bool { return i[j].Size > i[k].Size }
 func (i Items) Swap(j, k int)      { i[j], i[k] = i[k], i[j] }
 
 type model struct {
-	files      Items
-	folders    Items
-	cursor     int
-	viewMode   string // "files" or "folders"
-	confirming bool
-	err        error
-	windowSize tea.WindowSizeMsg
-	styles     styles
-	offset     int    // for scrolling
-	height     int    // visible height
-	width      int    // screen width
-	basePath   string // initial path to trim from display
+	files        Items
+	folders      Items
+	combined     Items // files and folders together, for the combined view
+	cursor       int
+	viewMode     string // "files", "folders" or "combined"
+	confirming   bool
+	err          error
+	windowSize   tea.WindowSizeMsg
+	styles       styles
+	offset       int        // for scrolling
+	height       int        // visible height
+	width        int        // screen width
+	basePath     string     // initial path to trim from display
+	total        int64      // grand total of unique file sizes, the denominator for shares
+	totalAlloc   int64      // allocated counterpart of total
+	root         Item       // the scan root with its recursive size
+	showHelp     bool       // full-screen key binding overlay
+	showDetail   bool       // full-screen details of the current item
+	showErrors   bool       // full-screen log of errors hit while scanning
+	status       string     // one-off message shown above the help line
+	filter       string     // only rows whose relative path contains this are shown
+	readOnlyFS   bool       // the scanned filesystem is mounted read-only
+	mount        *mountInfo // volume details when a mount point was scanned
+	volume       *mountInfo // the filesystem holding the scan root, wherever it's mounted
+	scope        string     // folder drilled into, "" at the top level
+	navStack     []navFrame
+	scanErrors   []scanError
+	errOffset    int                 // first visible line of the error log
+	visibleSizes bool                // folders show the sum of their visible subfolders
+	gone         bool                // the scan root can no longer be reached
+	transfer     *transfer           // move or copy being confirmed, nil for a delete
+	progress     *copyProgressMsg    // latest progress of a running copy
+	deleting     *deleteProgressMsg  // latest progress of a running deletion
+	sudo         *sudoRetry          // a deletion refused for lack of permission, offered through sudo
+	hashing      *hashProgressMsg    // latest progress of running checksums
+	scanning     *scanProgressMsg    // latest progress of a background scan
+	sizing       *folderSizesMsg     // folder sizes of a -progressive scan still arriving
+	checksums    map[string]string   // hex checksums by path, computed on request
+	selected     map[string]bool     // selected items by path, shared by every view and sort
+	openSelected map[string][]string // selected items held open, with the processes holding them, when confirming a delete
+	protected    map[string]int      // files with -protect extensions a confirmed delete would remove, by extension
+	protectAck   bool                // the first confirmation of a delete including protected files was given
+	refreshing   bool                // a rescan started by -refresh-on-focus is running
+	deleted      []deletion          // items removed this session, for the Q report
+	snapBefore   *snapshot           // baseline of the in-session diff
+	snapAfter    *snapshot           // latest snapshot compared against it
+	showDiff     bool                // full-screen diff between the two snapshots
+	showPie      bool                // full-screen chart of the current folder's immediate children
+	focus        focusLevel          // screen furniture hidden for screenshots and demos
+	bookmarks    []string            // pinned folders, saved across sessions
+	scanTime     time.Duration       // how long the scan being browsed took, for -timing
+	baseline     *Item               // item the RATIO column measures sizes against, nil when none is set
+	showRatio    bool                // show the RATIO column
+	ignore       []string            // the ignore list, saved across sessions; -no-ignore only stops scans using it
+	prompt       *prompt             // active text input, nil when not prompting
+	pane         *model              // the other pane of a split screen, nil when not split
+	paneRight    bool                // the other pane sits to the right of this one
+	paneID       int                 // tags the messages meant for this pane
+	lastPane     int                 // highest paneID given out, so a closed pane's messages never reach a new one
+	sortKey      sortKey
+	sortDesc     bool
+	thenBy       []sortClause // tie-breakers given after the first -sort key
+	opts         options
+	loc          locale // language used for sizes and times
+	keys         keymap
+}
+
+// options holds the settings chosen on the command line
+type options struct {
+	rootRow        bool // show the scan root as a summary row in the folders view
+	secure         bool // overwrite file contents before removal
+	inline         bool // render in the normal screen buffer so output stays in scrollback
+	pageSize       int  // most items listed at once; 0 fits the terminal
+	refreshOnFocus bool // rescan when the terminal window regains focus
+	progressive    bool // list the tree at once and size folders in the background
+
+	protect   string   // -protect as given, e.g. ".go,.docx"
+	protected []string // the extensions parsed from it, lower case with a leading dot
+	noIgnore  bool     // scan what the ignore list would leave out
+	timing    bool     // report how long each scan took and its throughput
+
+	deletionLog string // file every deletion is appended to
+	noLog       bool   // don't keep the deletion log
+
+	saveSnapshot string // write the scan to this file for later comparison
+	compare      string // snapshot file to compute size deltas against
+
+	ndjson     bool // print the scan as NDJSON instead of starting the TUI
+	text       int  // print this many rows as plain text instead of starting the TUI
+	metrics    bool // print the scan summary as Prometheus metrics instead of starting the TUI
+	metricsTop int  // largest folders and files exported by -metrics
+	fromNDJSON bool // browse an NDJSON listing read from stdin; deletion is disabled
+	readonly   bool // disable deletion, selection and custom commands entirely
+
+	allocated     bool   // show allocated disk usage instead of apparent sizes
+	locale        string // language for sizes and relative times, e.g. "de"
+	counts        bool   // show the ITEMS column with folder entry counts
+	avg           bool   // show the AVG column with each folder's average entry size
+	inodes        bool   // show the INODES column and the volume's inode usage
+	histogram     bool   // show a sparkline of the listed items' size distribution
+	combined      bool   // start in the combined view of files and folders
+	shallow       bool   // list only the immediate children of the folder being browsed
+	pareto        bool   // list only the largest items reaching paretoPercent of the total
+	paretoPercent int    // share of the total the Pareto view keeps
+	warnAt        int    // warn in a banner when the volume is at least this full, in percent; 0 disables it
+	caseSensitive bool   // match the filter's case exactly instead of ignoring it
+	units         string // size units: si, iec or exact
+	colors        string // color support to assume: auto, truecolor, 256, 16 or none
+	bytes         bool   // show the exact BYTES column next to the humanized size
+	percent       bool   // show each item's share of the total next to its size
+	dirSlash      bool   // mark folder names with a trailing separator, like ls -p
+	asTyped       bool   // show paths starting with the scan root as given on the command line
+	typedRoot     string // that root, cleaned; "" shows paths relative to the folder being browsed
+	sort          string // initial sort column, e.g. "size" or "name:desc"
+	depth         bool   // show the DEPTH column with each item's level below the base
+
+	checksum   string // checksum algorithm for #: crc32 or sha256
+	anonymize  bool   // replace path components with hashes in -ndjson output and reports
+	alertAbove string // -ndjson exits with exitThreshold above this size, e.g. "50GB"
+	quota      string // -quota as given, e.g. "20GB"; parsed into quotaBytes
+	quotaBytes int64  // shares and the full banner are measured against this when set
+
+	since string // -since as given, e.g. "7d"; parsed into scan.since
+	until string // -until as given; parsed into scan.until
+
+	scan scanOptions
+
+	exec string // command template run on the current item, {} is replaced by its path
 }
 
 type styles struct {
@@ -88,353 +274,1035 @@ type styles struct {
 	errorText     lipgloss.Style
 	confirmText   lipgloss.Style
 	selectionMark lipgloss.Style
+	rootRow       lipgloss.Style
+	looseRow      lipgloss.Style
+	deltaUp       lipgloss.Style
+	deltaDown     lipgloss.Style
+	match         lipgloss.Style // filter matches within names and paths
+	banner        lipgloss.Style // warnings across the top, such as a full disk
 }
 
 func initStyles() styles {
 	return styles{
 		title: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("#FFF")).
-			Background(lipgloss.Color("#0366d6")).
+			Foreground(colorWhite).
+			Background(colorTitle).
 			Padding(0, 1),
 		header: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("#FFF")).
-			Background(lipgloss.Color("#2f363d")),
+			Foreground(colorWhite).
+			Background(colorHeader),
 		selected: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("#FFF")).
-			Background(lipgloss.Color("#2ea043")),
+			Foreground(colorWhite).
+			Background(colorCursor),
 		normal: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFF")),
+			Foreground(colorWhite),
 		size: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#58a6ff")),
+			Foreground(colorSize),
 		helpText: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#8b949e")),
+			Foreground(colorMuted),
 		errorText: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#f85149")),
+			Foreground(colorError),
 		confirmText: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFF")).
-			Background(lipgloss.Color("#da3633")).
+			Foreground(colorWhite).
+			Background(colorDanger).
 			Padding(0, 1),
 		selectionMark: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#ff0000")),
+			Foreground(colorMark),
+		rootRow: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(colorSize),
+		looseRow: lipgloss.NewStyle().
+			Italic(true).
+			Foreground(colorSize),
+		deltaUp: lipgloss.NewStyle().
+			Foreground(colorError),
+		deltaDown: lipgloss.NewStyle().
+			Foreground(colorGrowth),
+		match: lipgloss.NewStyle().
+			Foreground(colorBlack).
+			Background(colorHighlight),
+		banner: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(colorWhite).
+			Background(colorDanger),
 	}
 }
 
-func getDirSize(path string) (int64, error) {
-	var size int64
-	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() {
-			size += info.Size()
+// Fallback screen size when the terminal can't be asked for it
+const (
+	fallbackWidth  = 100
+	fallbackHeight = 10
+)
+
+// terminalSize returns the size of the terminal on stdout, or the fallback
+// size when it isn't a terminal. Later resizes still arrive as
+// WindowSizeMsg.
+func terminalSize() (width, height int) {
+	w, h, err := term.GetSize(os.Stdout.Fd())
+	if err != nil || w <= 0 || h <= 0 {
+		return fallbackWidth, fallbackHeight
+	}
+	return w, h
+}
+
+func initialModel(path string, opts options) (model, error) {
+	if opts.progressive {
+		return startProgressive(path, opts)
+	}
+	res, err := loadScan(path, opts)
+	if err != nil {
+		return model{}, err
+	}
+	if opts.saveSnapshot != "" {
+		if err := saveSnapshot(opts.saveSnapshot, newSnapshot(res.root, res.rootSize, res.files, res.folders)); err != nil {
+			return model{}, fmt.Errorf("saving snapshot: %w", err)
 		}
-		return nil
-	})
-	return size, err
+	}
+	return newModel(res, opts)
 }
 
-func scanDirectory(path string) (Items, Items, error) {
-	var files, folders Items
+// newModel builds the model for a finished scan
+func newModel(res scanResult, opts options) (model, error) {
+	absPath, files, folders, rootSize := res.root, res.files, res.folders, res.rootSize
 
-	err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+	root := Item{Path: absPath, Size: rootSize, Allocated: res.rootAlloc, ItemCount: res.rootCount, Inodes: res.rootInodes, SizePartial: res.rootPartial, SizePending: res.rootPending, IsDir: true, IsRoot: true}
+	if opts.compare != "" {
+		snap, err := loadSnapshot(opts.compare)
 		if err != nil {
-			return nil
-		}
-		if strings.HasPrefix(filepath.Base(path), ".") {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
+			return model{}, fmt.Errorf("loading snapshot: %w", err)
 		}
+		files = applyDelta(files, snap.Files, absPath, false)
+		folders = applyDelta(folders, snap.Folders, absPath, true)
+		root.Delta = rootSize - snap.RootSize
+	}
 
-		if info.IsDir() {
-			size, err := getDirSize(path)
-			if err != nil {
-				return nil
-			}
-			folders = append(folders, Item{Path: path, Size: size})
-		} else {
-			files = append(files, Item{Path: path, Size: info.Size()})
-		}
-		return nil
-	})
+	// The root row is pinned above the size-sorted children
+	if opts.rootRow {
+		folders = append(Items{root}, folders...)
+	}
 
-	sort.Sort(files)
-	sort.Sort(folders)
+	loc, ok := lookupLocale(opts.locale)
+	status := ""
+	if len(res.skipped) > 0 {
+		status = fmt.Sprintf("Skipped pseudo filesystems: %s (use -include-virtual)", strings.Join(res.skipped, ", "))
+	}
+	if !ok {
+		status = fmt.Sprintf("Locale %q is not supported, using English", opts.locale)
+	}
+	keys, err := loadKeymap()
+	if err != nil {
+		keys = defaultKeymap()
+		status = "Key bindings: " + err.Error() + " - using defaults"
+	}
 
-	return files, folders, err
-}
+	viewMode := "files"
+	if opts.combined {
+		viewMode = "combined"
+	}
+	m := model{
+		status:     status,
+		loc:        loc,
+		files:      files,
+		folders:    folders,
+		combined:   combine(files, folders),
+		viewMode:   viewMode,
+		styles:     initStyles(),
+		basePath:   absPath,
+		total:      res.total,
+		totalAlloc: res.totalAlloc,
+		root:       root,
+		opts:       opts,
+		scanErrors: res.errors,
+		bookmarks:  loadBookmarks(),
+		ignore:     loadIgnore(),
+		keys:       keys,
+		scanTime:   res.elapsed,
+	}
+	if opts.timing && status == "" && res.elapsed > 0 && !res.rootPending {
+		m.status = "Scanned " + m.scanStats(res)
+	}
+
+	// Some terminals never send a WindowSizeMsg, so start from the real size
+	m.width, m.height = terminalSize()
+	m = m.detectVolume()
 
-func initialModel(path string) (model, error) {
-	absPath, err := filepath.Abs(path)
+	chain, err := parseSort(opts.sort)
 	if err != nil {
 		return model{}, err
 	}
+	m.thenBy = chain[1:]
+	return m.setSort(chain[0].key, chain[0].desc), nil
+}
 
-	files, folders, err := scanDirectory(absPath)
-	if err != nil {
-		return model{}, err
+func (m model) Init() tea.Cmd {
+	if m.opts.fromNDJSON {
+		return nil
+	}
+	if m.sizing != nil {
+		return forPane(m.paneID, tea.Batch(watchVolume(m.basePath), waitForCopy(m.sizing.ch)))
+	}
+	return forPane(m.paneID, watchVolume(m.basePath))
+}
+
+// grandTotal returns the unique file total in the active size mode
+func (m model) grandTotal() int64 {
+	if m.opts.allocated {
+		return m.totalAlloc
 	}
+	return m.total
+}
 
-	return model{
-		files:    files,
-		folders:  folders,
-		viewMode: "files",
-		styles:   initStyles(),
-		height:   10,  // Default height, will be updated on WindowSizeMsg
-		width:    100, // Default width, will be updated on WindowSizeMsg
-		basePath: absPath,
-	}, nil
+// sizeOf returns the item's size in the active size mode
+func (m model) sizeOf(item Item) int64 {
+	if m.opts.allocated {
+		return item.Allocated
+	}
+	return item.Size
 }
 
-func (m model) Init() tea.Cmd {
-	return nil
+// activeItems returns the list shown in the current view mode
+func (m model) activeItems() Items {
+	switch m.viewMode {
+	case "folders":
+		return m.folders
+	case "combined":
+		return m.combined
+	}
+	return m.files
+}
+
+// currentItem returns the item under the cursor
+func (m model) currentItem() (Item, bool) {
+	rows := m.rows()
+	if m.cursor < 0 || m.cursor >= rows.len() {
+		return Item{}, false
+	}
+	return *rows.at(m.cursor), true
+}
+
+// itemActions work on the item under the cursor, so they have nothing to do
+// in an empty list
+var itemActions = map[action]bool{
+	actSelect: true, actKeep: true, actOpenFolder: true, actIgnore: true, actInfo: true, actExactSize: true, actBaseline: true, actOpen: true, actExec: true,
+}
+
+// emptyHint explains why the list is empty and how to get items back
+func (m model) emptyHint() string {
+	switch {
+	case m.filter != "":
+		return fmt.Sprintf("No items match the filter - press %s to clear it or %s to edit it", m.keys.short(actClearFilter), m.keys.short(actFilter))
+	case m.scope != "":
+		return fmt.Sprintf("No items in this view - press %s to switch views or %s to go back", m.keys.short(actSwitchView), m.keys.short(actBack))
+	}
+	return fmt.Sprintf("No items in this view - press %s to switch views", m.keys.short(actSwitchView))
+}
+
+// nothingSelected is the status shown when an action needs a selection
+func (m model) nothingSelected() string {
+	if m.rows().len() == 0 {
+		return m.emptyHint()
+	}
+	return "Nothing selected - press Space to select items"
+}
+
+// clampView keeps the cursor on an existing item and scrolls so it stays
+// within the visible rows, e.g. after the window shrinks
+func (m model) clampView() model {
+	rows := m.rows().len()
+	visibleHeight := m.pageSize()
+
+	m.cursor = max(min(m.cursor, rows-1), 0)
+	if m.cursor >= m.offset+visibleHeight {
+		m.offset = m.cursor - visibleHeight + 1
+	}
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	m.offset = max(min(m.offset, rows-visibleHeight), 0)
+	return m
+}
+
+// moveCursor moves the cursor by delta rows, scrolling to keep it visible
+func (m model) moveCursor(delta int) model {
+	rows := m.rows().len()
+	switch {
+	case delta < 0 && m.cursor > 0:
+		m.cursor--
+		if m.cursor < m.offset {
+			m.offset = m.cursor
+		}
+	case delta > 0 && m.cursor < rows-1:
+		m.cursor++
+		if page := m.pageSize(); m.cursor >= m.offset+page {
+			m.offset = m.cursor - page + 1
+		}
+	}
+	return m
 }
 
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+// update handles a message for this pane alone
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
-			return m, tea.Quit
-		case "up", "k":
-			if m.cursor > 0 {
-				m.cursor--
-				if m.cursor < m.offset {
-					m.offset = m.cursor
-				}
+		m.status = ""
+		if m.prompt != nil {
+			return m.updatePrompt(msg)
+		}
+		if m.gone {
+			return m.updateGone(msg)
+		}
+		if m.sudo != nil && m.sudo.asking {
+			return m.updateSudo(msg)
+		}
+		if m.showHelp {
+			switch {
+			case m.keys.is(msg, actQuit):
+				return m, tea.Quit
+			case m.keys.is(msg, actHelp), msg.String() == "esc":
+				m.showHelp = false
 			}
-		case "down", "j":
-			items := m.files
-			if m.viewMode == "folders" {
-				items = m.folders
+			return m, nil
+		}
+		if m.showErrors {
+			return m.updateErrors(msg)
+		}
+		if m.showDiff {
+			switch {
+			case m.keys.is(msg, actQuit):
+				return m, tea.Quit
+			case m.keys.is(msg, actSnapshot):
+				m.showDiff = false
+				return m.takeSnapshot()
+			case msg.String() == "esc":
+				m.showDiff = false
 			}
-			if m.cursor < len(items)-1 {
-				m.cursor++
-				if m.cursor >= m.offset+m.height-4 {
-					m.offset = m.cursor - m.height + 5
+			return m, nil
+		}
+		if m.showPie {
+			switch {
+			case m.keys.is(msg, actQuit):
+				return m, tea.Quit
+			case m.keys.is(msg, actPie), msg.String() == "esc":
+				m.showPie = false
+			}
+			return m, nil
+		}
+		if m.confirming {
+			switch {
+			case msg.String() == "ctrl+c":
+				return m, tea.Quit
+			case m.keys.is(msg, actConfirm):
+				m.confirming = false
+				var ok bool
+				if m, ok = m.checkVolume(); !ok {
+					break
+				}
+				if m.transfer == nil && len(m.protected) > 0 && !m.protectAck {
+					// protected files take a second confirmation
+					m.confirming, m.protectAck = true, true
+					break
+				}
+				if t := m.transfer; t != nil {
+					m.transfer = nil
+					if t.copy {
+						return m, m.copySelected(t.dest)
+					}
+					m.status = "Moving to " + t.dest + "..."
+					return m, m.moveSelected(t.dest)
 				}
+				return m.deleteSelected()
+			case m.keys.is(msg, actCancel), msg.String() == "esc":
+				m.confirming, m.transfer = false, nil
 			}
-		case "pageup":
-			m.offset -= m.height - 4
+			return m, nil
+		}
+		if m.showDetail {
+			switch {
+			case m.keys.is(msg, actQuit):
+				return m, tea.Quit
+			case m.keys.is(msg, actInfo), msg.String() == "esc":
+				m.showDetail = false
+			}
+			return m, nil
+		}
+		if m.hashing != nil && msg.String() == "esc" {
+			return m.cancelHashing(), nil
+		}
+		act := m.keys.action(msg)
+		if itemActions[act] && m.rows().len() == 0 {
+			m.status = m.emptyHint()
+			return m, nil
+		}
+		if item, ok := m.currentItem(); ok && item.IsLoose && itemActions[act] && !looseActions[act] {
+			m.status = fmt.Sprintf("%s sums up the loose files - press %s to select them", looseName, m.keys.short(actSelect))
+			return m, nil
+		}
+		switch act {
+		case actQuit:
+			return m, tea.Quit
+		case actHelp:
+			m.showHelp = true
+		case actErrors:
+			m.showErrors = true
+		case actUp:
+			m = m.moveCursor(-1)
+		case actDown:
+			m = m.moveCursor(1)
+		case actPageUp:
+			page := m.pageSize()
+			m.offset -= page
 			if m.offset < 0 {
 				m.offset = 0
 			}
-			m.cursor -= m.height - 4
+			m.cursor -= page
 			if m.cursor < 0 {
 				m.cursor = 0
 			}
-		case "pagedown":
-			items := m.files
-			if m.viewMode == "folders" {
-				items = m.folders
-			}
-			m.offset += m.height - 4
-			maxOffset := len(items) - (m.height - 4)
+		case actPageDown:
+			rows, page := m.rows().len(), m.pageSize()
+			m.offset += page
+			maxOffset := rows - page
 			if m.offset > maxOffset {
 				m.offset = maxOffset
 			}
 			if m.offset < 0 {
 				m.offset = 0
 			}
-			m.cursor += m.height - 4
-			if m.cursor >= len(items) {
-				m.cursor = len(items) - 1
+			m.cursor += page
+			if m.cursor >= rows {
+				m.cursor = max(rows-1, 0)
 			}
-		case "home":
+		case actHome:
 			m.cursor = 0
 			m.offset = 0
-		case "end":
-			items := m.files
-			if m.viewMode == "folders" {
-				items = m.folders
-			}
-			m.cursor = len(items) - 1
-			m.offset = len(items) - (m.height - 4)
+		case actEnd:
+			rows := m.rows().len()
+			m.cursor = max(rows-1, 0)
+			m.offset = rows - m.pageSize()
 			if m.offset < 0 {
 				m.offset = 0
 			}
-		case "tab":
-			m.viewMode = map[string]string{
-				"files":   "folders",
-				"folders": "files",
-			}[m.viewMode]
+		case actSwitchView:
+			m.viewMode = nextView(m.viewMode)
 			m.cursor = 0
 			m.offset = 0
-		case " ":
-			if m.viewMode == "files" && m.cursor < len(m.files) {
-				m.files[m.cursor].IsSelected = !m.files[m.cursor].IsSelected
-			} else if m.viewMode == "folders" && m.cursor < len(m.folders) {
-				m.folders[m.cursor].IsSelected = !m.folders[m.cursor].IsSelected
-			}
-		case "d":
-			m.confirming = true
-		case "y":
-			if m.confirming {
-				items := &m.files
-				if m.viewMode == "folders" {
-					items = &m.folders
-				}
-				for i, item := range *items {
-					if item.IsSelected {
-						err := os.Remove(item.Path)
-						if err != nil {
-							m.err = err
-							break
-						}
-						(*items)[i].IsSelected = false
+		case actExec:
+			item, ok := m.currentItem()
+			if !ok {
+				break
+			}
+			if m.opts.readonly {
+				// An arbitrary command could just as well remove the item
+				m.status = readOnlyMessage
+				break
+			}
+			if m, ok = m.checkVolume(); !ok {
+				break
+			}
+			if m.opts.exec != "" {
+				return m, runExec(m.opts.exec, item.Path)
+			}
+			m.prompt = &prompt{
+				label: "Command ({} = path): ",
+				onSubmit: func(m model, template string) (model, tea.Cmd) {
+					if template == "" {
+						return m, nil
 					}
+					return m, runExec(template, item.Path)
+				},
+			}
+		case actOpen:
+			item, ok := m.currentItem()
+			if !ok {
+				break
+			}
+			if m, ok = m.checkVolume(); ok {
+				return m, runOpen(item.Path)
+			}
+		case actOpenFolder:
+			m = m.drillDown()
+		case actBack:
+			m = m.goBack()
+		case actTop:
+			m = m.goHome()
+		case actJumpLargest:
+			m = m.jumpToLargest()
+		case actBookmark:
+			m = m.toggleBookmark()
+		case actIgnore:
+			m = m.toggleIgnore()
+		case actNextBookmark:
+			return m.nextBookmark()
+		case actSplit:
+			return m.branch()
+		case actSwitchPane:
+			m = m.switchPane()
+		case actGoto:
+			m = m.promptGoto()
+		case actFilter:
+			m = m.promptFilter()
+		case actFilterCase:
+			m.opts.caseSensitive = !m.opts.caseSensitive
+			if m.opts.caseSensitive {
+				m.status = "Filter: case-sensitive"
+			} else {
+				m.status = "Filter: ignoring case"
+			}
+			m = m.setFilter(m.filter)
+		case actClearFilter:
+			if m.filter != "" {
+				m = m.setFilter("")
+			}
+		case actSort:
+			m = m.cycleSort()
+		case actReverseSort:
+			m = m.setSort(m.sortKey, !m.sortDesc)
+		case actSelect:
+			if m.opts.readonly {
+				m.status = readOnlyMessage
+				break
+			}
+			if rows := m.rows(); m.cursor < rows.len() {
+				if item := rows.at(m.cursor); item.IsLoose {
+					m = m.selectLoose(!m.isSelected(*item))
+				} else if item.deletable() {
+					m = m.setSelected(item.Path, !m.isSelected(*item))
 				}
-				m.confirming = false
 			}
-		case "n":
-			if m.confirming {
-				m.confirming = false
+		case actAllocated:
+			m.opts.allocated = !m.opts.allocated
+			m = m.setSort(m.sortKey, m.sortDesc)
+		case actVisibleSizes:
+			m.visibleSizes = !m.visibleSizes
+		case actInfo:
+			if _, ok := m.currentItem(); ok {
+				m.showDetail = true
+			}
+		case actBaseline:
+			m = m.setBaseline()
+		case actRatio:
+			m = m.toggleRatio()
+		case actExactSize:
+			if item, ok := m.currentItem(); ok {
+				m.status = m.exactSize(item)
+			}
+		case actUnits:
+			m.opts.units = nextUnits(m.opts.units)
+			m.status = "Units: " + m.opts.units
+		case actPercent:
+			m.opts.percent = !m.opts.percent
+		case actDirSlash:
+			m.opts.dirSlash = !m.opts.dirSlash
+		case actBytes:
+			m.opts.bytes = !m.opts.bytes
+		case actDepth:
+			m.opts.depth = !m.opts.depth
+			if !m.opts.depth && m.sortKey == sortByDepth {
+				m = m.setSort(sortBySize, true)
+			}
+		case actCounts:
+			m.opts.counts = !m.opts.counts
+			if !m.opts.counts && m.sortKey == sortByCount {
+				m = m.setSort(sortBySize, true)
+			}
+		case actAvg:
+			m.opts.avg = !m.opts.avg
+			if !m.opts.avg && m.sortKey == sortByAvg {
+				m = m.setSort(sortBySize, true)
+			}
+		case actShallow:
+			m.opts.shallow = !m.opts.shallow
+			m.cursor, m.offset = 0, 0
+			if m.opts.shallow {
+				m.status = "Shallow: immediate children only"
+			} else {
+				m.status = "Deep: everything below this folder"
+			}
+		case actPareto:
+			m.opts.pareto = !m.opts.pareto
+			m.cursor, m.offset = 0, 0
+			if m.opts.pareto {
+				m.status = fmt.Sprintf("Pareto: the largest items making up %d%% of the total", m.opts.paretoPercent)
+			} else {
+				m.status = "Showing every item"
+			}
+		case actPie:
+			m.showPie = true
+		case actHistogram:
+			m.opts.histogram = !m.opts.histogram
+		case actFocus:
+			m.focus = (m.focus + 1) % focusLevels
+		case actInodes:
+			m.opts.inodes = !m.opts.inodes
+			if !m.opts.inodes && m.sortKey == sortByInodes {
+				m = m.setSort(sortBySize, true)
+			}
+		case actCopyCommand:
+			cmd := m.commandLine()
+			m.status = "Copied: " + cmd
+			return m, copyToClipboard(cmd)
+		case actKeep:
+			if m.opts.readonly {
+				m.status = readOnlyMessage
+				break
+			}
+			m = m.promptKeep()
+		case actSnapshot:
+			return m.takeSnapshot()
+		case actChecksum:
+			return m.hashSelected()
+		case actSudo:
+			if m.sudo == nil {
+				m.status = "Nothing to retry - sudo is only offered after a deletion is refused"
+				break
+			}
+			m.sudo.asking = true
+		case actQuitReport:
+			m = m.promptReport()
+		case actMove:
+			m = m.promptTransfer(false)
+		case actCopy:
+			m = m.promptTransfer(true)
+		case actDelete:
+			if reason := m.deletionDisabled(); reason != "" {
+				m.status = reason
+				break
+			}
+			if m.deleting != nil {
+				m.status = "Wait for the current deletion to finish"
+				break
 			}
+			if len(m.selectedItems()) == 0 {
+				m.status = m.nothingSelected()
+				break
+			}
+			m.confirming, m.openSelected = true, m.inUse()
+			m.protected, m.protectAck = m.protectedSelected(), false
+		}
+	case execDoneMsg:
+		m.status = msg.String()
+	case snapshotMsg:
+		m = m.addSnapshot(msg)
+	case moveDoneMsg:
+		m = m.finishMove(msg)
+	case copyProgressMsg:
+		m.progress = &msg
+		return m, waitForCopy(msg.ch)
+	case copyDoneMsg:
+		m.progress = nil
+		m = m.finishCopy(msg)
+	case deleteProgressMsg:
+		m.deleting = &msg
+		return m, waitForCopy(msg.ch)
+	case deleteDoneMsg:
+		m = m.finishDelete(msg)
+	case hashProgressMsg:
+		m.hashing = &msg
+		return m, waitForCopy(msg.ch)
+	case hashDoneMsg:
+		m = m.finishHash(msg)
+	case sudoDoneMsg:
+		m = m.finishSudo(msg)
+	case tea.FocusMsg:
+		return m.refreshOnFocus()
+	case refreshMsg:
+		m = m.applyRefresh(msg)
+	case folderSizesMsg:
+		m = m.applySizes(msg)
+		if msg.final == nil {
+			return m, waitForCopy(msg.ch)
+		}
+	case scanProgressMsg:
+		m.scanning = &msg
+		return m, waitForCopy(msg.ch)
+	case rescanMsg:
+		m.scanning = nil
+		if msg.err != nil {
+			m.status = "Can't scan: " + msg.err.Error()
+			break
+		}
+		previous := m.basePath
+		m = m.withScan(msg.res).clampView()
+		m.status = "Scanned " + m.basePath
+		if m.opts.timing {
+			m.status += ": " + m.scanStats(msg.res)
+		}
+		m.gone = false
+		if m.basePath != previous {
+			// The old root's checks stop once they see it's no longer current
+			return m, watchVolume(m.basePath)
+		}
+	case volumeMsg:
+		if msg.path != m.basePath {
+			break
+		}
+		m.gone = !msg.available
+		return m, watchVolume(m.basePath)
+	case tea.MouseMsg:
+		if !m.showHelp && !m.showDetail && !m.showErrors && !m.showDiff && !m.showPie && !m.confirming {
+			m = m.handleMouse(msg)
 		}
 	case tea.WindowSizeMsg:
 		m.windowSize = msg
-		m.height = msg.Height
-		m.width = msg.Width
+		m = m.resized()
 	}
 	return m, nil
 }
 
-func (m model) View() string {
+// screen renders this pane alone, filling its width
+func (m model) screen() string {
 	if m.err != nil {
 		return m.styles.errorText.Render(fmt.Sprintf("Error: %v", m.err))
 	}
+	if m.gone {
+		return m.goneView()
+	}
+	if m.showHelp {
+		return m.helpView()
+	}
+	if m.showDetail {
+		return m.detailView()
+	}
+	if m.showErrors {
+		return m.errorsView()
+	}
+	if m.showDiff {
+		return m.diffView()
+	}
+	if m.showPie {
+		return m.pieView()
+	}
+	if m.confirming {
+		return m.confirmView()
+	}
 
-	var s strings.Builder
+	rows := m.rows()
+	cols := m.columns()
+	top := m.topRegion(rows, cols)
 
-	// Get current items list
-	items := m.files
-	if m.viewMode == "folders" {
-		items = m.folders
+	// Handle empty list
+	if rows.len() == 0 {
+		if m.filter != "" {
+			middle := []string{"", m.styles.normal.Render(m.emptyHint())}
+			return m.compose(top, middle, m.bottomRegion([]hint{
+				{"Edit filter", []action{actFilter}},
+				{"Clear filter", []action{actClearFilter}},
+				{"Quit", []action{actQuit}},
+			}))
+		}
+		hints := []hint{{"Switch View", []action{actSwitchView}}}
+		if m.scope != "" {
+			hints = append(hints, hint{"Back", []action{actBack}}, hint{"Top", []action{actTop}})
+		}
+		hints = append(hints, hint{"Help", []action{actHelp}}, hint{"Quit", []action{actQuit}})
+		middle := []string{"", m.styles.normal.Render(m.emptyHint())}
+		return m.compose(top, middle, m.bottomRegion(hints))
 	}
 
-	// Title with item count
-	title := fmt.Sprintf(" Disk Usage Analyzer - %s (%d/%d) ",
-		strings.ToUpper(m.viewMode),
-		min(m.cursor+1, max(len(items), 1)),
-		len(items),
-	)
-	s.WriteString(m.styles.title.Render(title) + "\n\n")
+	bottom := m.bottomRegion(helpLine)
+	visibleHeight := m.listHeight(rows, top, bottom)
 
-	// Calculate widths based on screen size
-	selectWidth := 3                                                  // Width for selection indicator (including brackets) [*]
-	sizeWidth := 8                                                    // Fixed width for size column
-	minPathWidth := 30                                                // Minimum width for path
-	nameWidth := m.width - sizeWidth - selectWidth - minPathWidth - 6 // -6 for spacing
+	// Keep the offset within bounds
+	m.offset = max(min(m.offset, rows.len()-visibleHeight), 0)
+	endIdx := min(m.offset+visibleHeight, rows.len())
 
-	// If we still have too much space, limit name column to something reasonable
-	if nameWidth > 100 {
-		nameWidth = 100
+	// Items
+	var sums map[string][2]int64
+	if m.visibleSizes && m.viewMode == "folders" {
+		sums = m.visibleSums()
 	}
+	var middle []string
+	for row := m.offset; row < endIdx; row++ {
+		item := *rows.at(row)
+		if sums != nil {
+			item = withVisibleSize(item, sums)
+		}
+		selected := " "
+		if m.isSelected(item) {
+			selected = m.styles.selectionMark.Render("*")
+		}
+		line := fmt.Sprintf("[%s] %s", selected, m.itemLine(item, cols))
 
-	// Path gets whatever is left
-	pathWidth := m.width - sizeWidth - nameWidth - selectWidth - 6
+		switch {
+		case row == m.cursor:
+			line = m.styles.selected.Render(line)
+		case item.IsRoot:
+			line = m.styles.rootRow.Render(line)
+		case item.IsLoose:
+			line = m.styles.looseRow.Render(line)
+		default:
+			line = m.styles.normal.Render(line)
+		}
+		middle = append(middle, line)
+	}
+	if rows.rest.count > 0 && endIdx == rows.len() {
+		middle = append(middle, m.paretoRestView(rows.rest))
+	}
 
-	// Header
-	header := fmt.Sprintf("[%s] %*s %-*s %s",
-		" ",
-		sizeWidth, "SIZE",
-		nameWidth, "NAME",
-		"PATH",
-	)
-	s.WriteString(m.styles.header.Render(header) + "\n")
+	return m.compose(top, middle, bottom)
+}
 
-	// Handle empty list
-	if len(items) == 0 {
-		s.WriteString(m.styles.normal.Render("\nNo items found in this view"))
-		s.WriteString(m.styles.helpText.Render("\n\nTab: Switch View • q: Quit"))
-		return s.String()
+func main() {
+	opts := options{scan: defaultScanOptions()}
+	flag.BoolVar(&opts.rootRow, "root", false, "show the scan root as a total row at the top of the folders view")
+	flag.StringVar(&opts.protect, "protect", "", "comma-separated file `extensions`, such as .go,.docx,.psd, that a delete only removes after a second confirmation naming them")
+	flag.StringVar(&opts.deletionLog, "deletion-log", defaultDeletionLog(), "`file` every deletion is appended to, one JSON line each")
+	flag.BoolVar(&opts.noLog, "no-log", false, "don't record deletions in the -deletion-log file")
+	flag.BoolVar(&opts.secure, "secure", false, "overwrite files with zeros before deleting them (best-effort on SSDs and copy-on-write filesystems)")
+	flag.BoolVar(&opts.progressive, "progressive", false, "start browsing as soon as the tree is listed, filling in folder sizes in the background")
+	flag.BoolVar(&opts.inline, "inline", false, "run without the alternate screen so the final view stays in the terminal")
+	flag.IntVar(&opts.pageSize, "page-size", 0, "list at most `n` items at once, paging even when the terminal could show more (default: fit the terminal)")
+	flag.BoolVar(&opts.refreshOnFocus, "refresh-on-focus", false, "rescan when the terminal window regains focus, for terminals and multiplexers that report focus events")
+	flag.StringVar(&opts.saveSnapshot, "save-snapshot", "", "write the scan results to `file` for a later -compare")
+	flag.StringVar(&opts.compare, "compare", "", "show size changes since the snapshot in `file`")
+	flag.BoolVar(&opts.ndjson, "ndjson", false, "print the scan as newline-delimited JSON instead of starting the TUI")
+	flag.IntVar(&opts.text, "text", 0, "print the first `n` rows of the listing and the totals as plain text instead of starting the TUI")
+	flag.BoolVar(&opts.metrics, "metrics", false, "print the scan summary and the largest folders and files as Prometheus metrics instead of starting the TUI")
+	flag.IntVar(&opts.metricsTop, "metrics-top", defaultMetricsTop, "largest folders and files, `n` of each, exported by -metrics")
+	flag.BoolVar(&opts.fromNDJSON, "from-ndjson", false, "browse an NDJSON listing read from stdin instead of scanning (deletion is disabled)")
+	flag.BoolVar(&opts.readonly, "readonly", false, "analyze only: disable deletion, selection and -exec commands")
+	flag.BoolVar(&opts.allocated, "allocated", false, "show allocated disk usage instead of apparent file sizes")
+	flag.BoolVar(&opts.scan.compressed, "compressed", false, "mark the files the filesystem stores compressed and size them by the space they take where it's reported (NTFS, APFS, ZFS); implies -allocated")
+	flag.StringVar(&opts.locale, "locale", "", "`language` for sizes and times: en, de, fr, es or pt (default en)")
+	flag.BoolVar(&opts.counts, "counts", false, "show how many files and subfolders each folder contains (toggle with c)")
+	flag.BoolVar(&opts.avg, "avg", false, "show each folder's average entry size, its size divided by the entries it contains (toggle with a)")
+	flag.BoolVar(&opts.caseSensitive, "case-sensitive", false, "match the filter's case exactly instead of ignoring it (toggle with F)")
+	flag.BoolVar(&opts.shallow, "shallow", false, "list only the immediate children of the scanned folder instead of everything below it (toggle with D)")
+	flag.BoolVar(&opts.pareto, "pareto", false, "list only the largest items that together make up -pareto-percent of the total, collapsing the rest into one row (toggle with P)")
+	flag.IntVar(&opts.paretoPercent, "pareto-percent", defaultParetoPercent, "share of the total, in `percent`, that -pareto keeps")
+	flag.IntVar(&opts.warnAt, "warn-at", defaultWarnAt, "show a red banner when the scanned volume is at least this `percent` full; 0 turns it off")
+	flag.BoolVar(&opts.combined, "combined", false, "start in the combined view, listing the files and folders of each folder together (Tab cycles views)")
+	flag.BoolVar(&opts.histogram, "histogram", false, "show a sparkline of the listed items' size distribution below the title (toggle with H)")
+	flag.BoolVar(&opts.inodes, "inodes", false, "show the inodes each folder uses and the volume's inode usage (toggle with I)")
+	flag.StringVar(&opts.colors, "colors", "auto", "color `support` of the terminal: auto (detected), truecolor, 256, 16 or none")
+	flag.StringVar(&opts.units, "units", unitsSI, "size `units`: si (kB, MB), iec (KiB, MiB) or exact byte counts")
+	flag.BoolVar(&opts.bytes, "bytes", false, "show exact byte counts in a BYTES column next to the size (toggle with b)")
+	flag.BoolVar(&opts.percent, "percent", false, "show each item's share of the total next to its size (toggle with %)")
+	flag.BoolVar(&opts.dirSlash, "dir-slash", true, "mark folder names with a trailing / in the folders and combined views; -dir-slash=false turns it off (toggle with \\)")
+	flag.BoolVar(&opts.asTyped, "as-typed", false, "show paths starting with the scanned path as typed, such as ../logs/app, instead of relative to the folder being browsed")
+	flag.BoolVar(&opts.depth, "depth", false, "show each item's depth below the scanned directory (toggle with L)")
+	flag.StringVar(&opts.sort, "sort", "size", "sort `columns`, comma-separated with ties broken by the next (size, delta, count, avg, depth, inodes, name, ext, path, mtime, created), each with optional :asc or :desc; also orders -ndjson output, path gives tree order")
+	flag.IntVar(&opts.scan.workers, "concurrency", opts.scan.workers, "folders sized in parallel; use 1 for spinning disks and network mounts, higher values for SSDs")
+	flag.BoolVar(&opts.scan.dirOverhead, "dir-overhead", false, "count the space used by directories themselves, as du does")
+	flag.BoolVar(&opts.scan.created, "created", false, "look up when each file and folder was created and show it in a CREATED column")
+	flag.BoolVar(&opts.scan.virtual, "include-virtual", false, "descend into pseudo filesystems such as /proc and /sys, which are skipped by default")
+	flag.StringVar(&opts.scan.symlinks, "symlinks", symlinksLink, "how to handle symbolic `links`: link (list them with their own size), skip (leave them out) or target (list them with the size of the file they point to)")
+	flag.BoolVar(&opts.noIgnore, "no-ignore", false, "scan everything, including what the ignore list (X in the TUI) leaves out")
+	flag.BoolVar(&opts.timing, "timing", false, "show how long each scan took and its throughput, and include the duration in -text, -ndjson and Q reports")
+	flag.BoolVar(&opts.scan.noCache, "no-cache", false, "size every folder instead of reusing unchanged folder sizes from the last scan")
+	flag.StringVar(&opts.checksum, "checksum", checksumSHA256, "`algorithm` for checksums computed with #: crc32 (fast) or sha256")
+	flag.BoolVar(&opts.anonymize, "anonymize", false, "replace file and folder names with hashes in -ndjson output and Q reports, keeping depth, extensions and sizes")
+	flag.StringVar(&opts.alertAbove, "alert-above", "", "with -ndjson, exit with status 5 when the scanned folder is larger than `size` (e.g. 50GB)")
+	flag.StringVar(&opts.quota, "quota", "", "measure shares and the full-disk banner against a storage quota of `size` (e.g. 20GB) instead of the whole volume")
+	flag.StringVar(&opts.since, "since", "", "only count files modified since `when`: a date (2006-01-02) or a duration ago (24h, 7d, 2w)")
+	flag.StringVar(&opts.until, "until", "", "only count files modified before `when`; a bare date includes that day")
+	flag.StringVar(&opts.exec, "exec", "", "command `template` run on the current item with x, {} is replaced by its path (passed as one argument, never through a shell)")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: diskusage [flags] <directory_path>")
+		fmt.Fprintln(flag.CommandLine.Output(), "       diskusage [flags] -from-ndjson < scan.ndjson")
+		flag.PrintDefaults()
 	}
+	flag.Parse()
 
-	// Calculate visible range and items
-	visibleHeight := m.height - 4
-	if visibleHeight < 1 {
-		visibleHeight = 1
+	if !validUnits(opts.units) {
+		fmt.Fprintf(os.Stderr, "Invalid -units %q: use si, iec or exact\n", opts.units)
+		os.Exit(exitUsage)
 	}
 
-	// Update offset bounds
-	if m.offset < 0 {
-		m.offset = 0
+	if opts.scan.compressed {
+		opts.allocated = true
 	}
-	maxOffset := len(items) - visibleHeight
-	if maxOffset < 0 {
-		maxOffset = 0
+	if !validSymlinks(opts.scan.symlinks) {
+		fmt.Fprintf(os.Stderr, "Invalid -symlinks %q: use link, skip or target\n", opts.scan.symlinks)
+		os.Exit(exitUsage)
 	}
-	if m.offset > maxOffset {
-		m.offset = maxOffset
+	if !validChecksum(opts.checksum) {
+		fmt.Fprintf(os.Stderr, "Invalid -checksum %q: use crc32 or sha256\n", opts.checksum)
+		os.Exit(exitUsage)
 	}
 
-	endIdx := m.offset + visibleHeight
-	if endIdx > len(items) {
-		endIdx = len(items)
+	chain, err := parseSort(opts.sort)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -sort: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	// sorting by creation time needs the times looked up
+	if slices.ContainsFunc(chain, func(c sortClause) bool { return c.key == sortByCreated }) {
+		opts.scan.created = true
 	}
 
-	visibleItems := items[m.offset:endIdx]
+	now := time.Now()
+	if opts.scan.since, err = parseTimeBound(opts.since, now, false); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -since: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	if opts.scan.until, err = parseTimeBound(opts.until, now, true); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -until: %v\n", err)
+		os.Exit(exitUsage)
+	}
 
-	// Items
-	for i, item := range visibleItems {
-		name := filepath.Base(item.Path)
-		relPath := getRelativePath(filepath.Dir(item.Path), m.basePath)
-		selected := " "
-		if item.IsSelected {
-			selected = m.styles.selectionMark.Render("*")
+	var alertAbove int64
+	if opts.alertAbove != "" {
+		n, err := humanize.ParseBytes(opts.alertAbove)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -alert-above %q: use a size such as 50GB or 2GiB\n", opts.alertAbove)
+			os.Exit(exitUsage)
 		}
+		alertAbove = int64(n)
+	}
 
-		// Format line with selection at start
-		line := fmt.Sprintf("[%s] %*s %-*s %s",
-			selected,
-			sizeWidth, m.styles.size.Render(humanize.Bytes(uint64(item.Size))),
-			nameWidth, truncateString(name, nameWidth),
-			truncateFromStart(relPath, pathWidth),
-		)
+	if opts.refreshOnFocus && (opts.compare != "" || opts.fromNDJSON) {
+		fmt.Fprintln(os.Stderr, "-refresh-on-focus needs a local scan without -compare")
+		os.Exit(exitUsage)
+	}
+
+	if opts.progressive && (opts.compare != "" || opts.saveSnapshot != "" || opts.fromNDJSON) {
+		fmt.Fprintln(os.Stderr, "-progressive needs a local scan without -compare or -save-snapshot")
+		os.Exit(exitUsage)
+	}
 
-		if i+m.offset == m.cursor {
-			s.WriteString(m.styles.selected.Render(line))
-		} else {
-			s.WriteString(m.styles.normal.Render(line))
+	if opts.warnAt < 0 || opts.warnAt > 100 {
+		fmt.Fprintln(os.Stderr, "-warn-at must be between 0 and 100")
+		os.Exit(exitUsage)
+	}
+
+	if opts.paretoPercent < 1 || opts.paretoPercent > 100 {
+		fmt.Fprintln(os.Stderr, "-pareto-percent must be between 1 and 100")
+		os.Exit(exitUsage)
+	}
+
+	if opts.quota != "" {
+		n, err := humanize.ParseBytes(opts.quota)
+		if err != nil || n == 0 {
+			fmt.Fprintf(os.Stderr, "Invalid -quota %q: use a size such as 20GB or 5GiB\n", opts.quota)
+			os.Exit(exitUsage)
 		}
-		s.WriteString("\n")
+		opts.quotaBytes = int64(n)
 	}
 
-	// Confirmation dialog
-	if m.confirming {
-		s.WriteString("\n" + m.styles.confirmText.Render("Delete selected items? (y/n)"))
+	if opts.pageSize < 0 {
+		fmt.Fprintln(os.Stderr, "-page-size can't be negative")
+		os.Exit(exitUsage)
 	}
 
-	// Help
-	help := "\n↑/↓: Navigate • PgUp/PgDn: Page • Home/End: Jump • Tab: Switch View • Space: Select • d: Delete • q: Quit"
-	s.WriteString(m.styles.helpText.Render(help))
+	if opts.metricsTop < 0 {
+		fmt.Fprintln(os.Stderr, "-metrics-top can't be negative")
+		os.Exit(exitUsage)
+	}
 
-	return s.String()
-}
+	if opts.metrics && opts.anonymize {
+		// A fresh salt every run would start new series on every scrape
+		fmt.Fprintln(os.Stderr, "-metrics can't be combined with -anonymize")
+		os.Exit(exitUsage)
+	}
 
-func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: go run main.go <directory_path>")
-		os.Exit(1)
+	if err := setColorProfile(opts.colors); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitUsage)
+	}
+
+	opts.protected = parseProtected(opts.protect)
+	if !opts.noIgnore {
+		opts.scan.ignore = loadIgnore()
+	}
+
+	if opts.scan.workers < 1 {
+		fmt.Fprintln(os.Stderr, "-concurrency must be at least 1")
+		os.Exit(exitUsage)
 	}
 
-	initialModel, err := initialModel(os.Args[1])
+	if flag.NArg() < 1 && !opts.fromNDJSON {
+		flag.Usage()
+		os.Exit(exitUsage)
+	}
+	if opts.asTyped && !opts.fromNDJSON {
+		opts.typedRoot = filepath.Clean(flag.Arg(0))
+	}
+
+	if opts.ndjson {
+		res, err := loadScan(flag.Arg(0), opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error scanning: %v\n", err)
+			os.Exit(errorExitCode(err))
+		}
+		sortItems(res.files, chain, opts.allocated)
+		sortItems(res.folders, chain, opts.allocated)
+		var anon *anonymizer
+		if opts.anonymize {
+			anon = newAnonymizer()
+		}
+		if !opts.timing {
+			// the duration changes every run, so it's only exported on request
+			res.elapsed = 0
+		}
+		if err := writeNDJSON(os.Stdout, res, anon); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing NDJSON: %v\n", err)
+			os.Exit(exitError)
+		}
+		if n := len(res.errors); n > 0 {
+			fmt.Fprintf(os.Stderr, "%d entries couldn't be read\n", n)
+		}
+		os.Exit(scanExitCode(res, alertAbove, opts.allocated))
+	}
+
+	if opts.metrics {
+		start := time.Now()
+		res, err := loadScan(flag.Arg(0), opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error scanning: %v\n", err)
+			os.Exit(errorExitCode(err))
+		}
+		if err := writeMetrics(os.Stdout, res, opts.metricsTop, opts.allocated, time.Since(start)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing metrics: %v\n", err)
+			os.Exit(exitError)
+		}
+		os.Exit(scanExitCode(res, alertAbove, opts.allocated))
+	}
+
+	if opts.text > 0 {
+		res, err := loadScan(flag.Arg(0), opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error scanning: %v\n", err)
+			os.Exit(errorExitCode(err))
+		}
+		width := defaultTextWidth
+		if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+			width = n
+		}
+		text, err := renderText(res, opts, width, opts.text)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		fmt.Print(text)
+		return
+	}
+
+	initialModel, err := initialModel(flag.Arg(0), opts)
 	if err != nil {
 		fmt.Printf("Error initializing: %v\n", err)
-		os.Exit(1)
+		os.Exit(errorExitCode(err))
+	}
+
+	programOpts := []tea.ProgramOption{tea.WithMouseCellMotion()}
+	if !opts.inline {
+		programOpts = append(programOpts, tea.WithAltScreen())
+	}
+	if opts.refreshOnFocus {
+		programOpts = append(programOpts, tea.WithReportFocus())
+	}
+	if opts.fromNDJSON {
+		// stdin carries the listing, so read keys from the terminal instead
+		programOpts = append(programOpts, tea.WithInputTTY())
 	}
 
-	p := tea.NewProgram(
-		initialModel,
-		tea.WithAltScreen(),
-	)
+	p := tea.NewProgram(initialModel, programOpts...)
 
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error running program: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitError)
 	}
 }
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// testModel scans n files, largest first, and builds a model over them
+func testModel(t *testing.T, n int) model {
+	t.Helper()
+	isolateUserDirs(t)
+	dir := t.TempDir()
+	for i := range n {
+		writeFile(t, filepath.Join(dir, fmt.Sprintf("file%02d", i)), 1000-i)
+	}
+	m, err := initialModel(dir, options{scan: defaultScanOptions(), sort: "size"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// resize sends m a new terminal size
+func resize(m model, width, height int) model {
+	next, _ := m.Update(tea.WindowSizeMsg{Width: width, Height: height})
+	return next.(model)
+}
+
+func TestResizeKeepsCursorVisible(t *testing.T) {
+	for _, cursor := range []int{0, 20, 49} {
+		m := resize(testModel(t, 50), 100, 40)
+		m.cursor = cursor
+		m = m.clampView()
+		for _, height := range []int{12, 40, 100, 3, 40} {
+			m = resize(m, 100, height)
+			rows, page := m.rows().len(), m.pageSize()
+			if m.cursor != cursor {
+				t.Errorf("height %d: cursor moved from %d to %d", height, cursor, m.cursor)
+			}
+			if m.cursor < m.offset || m.cursor >= m.offset+page {
+				t.Errorf("height %d: cursor %d outside rows %d-%d", height, m.cursor, m.offset, m.offset+page-1)
+			}
+			if m.offset < 0 || m.offset > max(rows-page, 0) {
+				t.Errorf("height %d: offset %d leaves rows unused below %d items", height, m.offset, rows)
+			}
+		}
+	}
+}
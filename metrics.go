@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultMetricsTop is how many of the largest folders and files -metrics
+// exports as their own series
+const defaultMetricsTop = 10
+
+// writeMetrics writes the scan summary in the Prometheus text exposition
+// format, for a node_exporter textfile collector or a push gateway. Every
+// series is labeled with the scanned path; the top largest folders and
+// files, ranked by allocated size when allocated is set, get one series each,
+// and the rest are summed up in one more labeled kind="other".
+func writeMetrics(w io.Writer, res scanResult, top int, allocated bool, elapsed time.Duration) error {
+	bw := bufio.NewWriter(w)
+	root := `path="` + metricLabel(res.root) + `"`
+	gauge := func(name, help string) {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+	partial := 0
+	if res.rootPartial {
+		partial = 1
+	}
+
+	gauge("diskusage_bytes", "Apparent size of the scanned folder in bytes.")
+	fmt.Fprintf(bw, "diskusage_bytes{%s} %d\n", root, res.rootSize)
+	gauge("diskusage_allocated_bytes", "Disk space allocated to the scanned folder in bytes.")
+	fmt.Fprintf(bw, "diskusage_allocated_bytes{%s} %d\n", root, res.rootAlloc)
+	gauge("diskusage_entries", "Files and folders below the scanned folder.")
+	fmt.Fprintf(bw, "diskusage_entries{%s} %d\n", root, res.rootCount)
+	gauge("diskusage_inodes", "Inodes used by the scanned folder and everything below it.")
+	fmt.Fprintf(bw, "diskusage_inodes{%s} %d\n", root, res.rootInodes)
+	gauge("diskusage_scan_errors", "Entries that couldn't be read during the scan.")
+	fmt.Fprintf(bw, "diskusage_scan_errors{%s} %d\n", root, len(res.errors))
+	gauge("diskusage_partial", "1 if parts of the scanned folder couldn't be read, so its size is too small.")
+	fmt.Fprintf(bw, "diskusage_partial{%s} %d\n", root, partial)
+	gauge("diskusage_scan_duration_seconds", "Time the scan took.")
+	fmt.Fprintf(bw, "diskusage_scan_duration_seconds{%s} %.3f\n", root, elapsed.Seconds())
+
+	bySize := []sortClause{{sortBySize, true}}
+	sortItems(res.folders, bySize, allocated)
+	sortItems(res.files, bySize, allocated)
+	series := func(kind string, all Items) {
+		items, rest := all[:min(top, len(all))], otherItems(res.root, all, top)
+		// With -metrics-top 0 everything goes into the other series
+		if len(items) == 0 && rest.count == 0 {
+			return
+		}
+		other := root + `,kind="other"`
+		gauge("diskusage_"+kind+"_bytes", fmt.Sprintf("Apparent size of the largest %ss in bytes.", kind))
+		for _, item := range items {
+			fmt.Fprintf(bw, "diskusage_%s_bytes{%s,%s=\"%s\"} %d\n", kind, root, kind, metricLabel(item.Path), item.Size)
+		}
+		if rest.count > 0 {
+			fmt.Fprintf(bw, "diskusage_%s_bytes{%s} %d\n", kind, other, rest.size)
+		}
+		gauge("diskusage_"+kind+"_allocated_bytes", fmt.Sprintf("Disk space allocated to the largest %ss in bytes.", kind))
+		for _, item := range items {
+			fmt.Fprintf(bw, "diskusage_%s_allocated_bytes{%s,%s=\"%s\"} %d\n", kind, root, kind, metricLabel(item.Path), item.Allocated)
+		}
+		if rest.count > 0 {
+			fmt.Fprintf(bw, "diskusage_%s_allocated_bytes{%s} %d\n", kind, other, rest.allocated)
+			gauge("diskusage_"+kind+"_items", fmt.Sprintf("%ss beyond the largest, summed up in the kind=\"other\" series.", strings.ToUpper(kind[:1])+kind[1:]))
+			fmt.Fprintf(bw, "diskusage_%s_items{%s} %d\n", kind, other, rest.count)
+		}
+	}
+	series("folder", res.folders)
+	series("file", res.files)
+	return bw.Flush()
+}
+
+// metricsRest sums up the items past the top exported by -metrics
+type metricsRest struct {
+	count           int
+	size, allocated int64
+}
+
+// otherItems sums up the items after the first top. Folders nested in
+// another scanned folder are already part of its size and only add to the
+// count, so the folder series add up to the folders' total.
+func otherItems(root string, items Items, top int) metricsRest {
+	var rest metricsRest
+	if top >= len(items) {
+		return rest
+	}
+	scanned := make(map[string]bool, len(items))
+	for _, item := range items {
+		scanned[item.Path] = true
+	}
+	for _, item := range items[top:] {
+		rest.count++
+		if item.IsDir && nestedIn(scanned, root, item.Path) {
+			continue
+		}
+		rest.size += item.Size
+		rest.allocated += item.Allocated
+	}
+	return rest
+}
+
+// nestedIn reports whether a folder above path, below root, is in dirs
+func nestedIn(dirs map[string]bool, root, path string) bool {
+	for p := filepath.Dir(path); p != root && p != filepath.Dir(p); p = filepath.Dir(p) {
+		if dirs[p] {
+			return true
+		}
+	}
+	return false
+}
+
+// metricLabel escapes a label value as the exposition format requires. Bytes
+// that aren't UTF-8 become U+FFFD, since the format allows nothing else.
+func metricLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(strings.ToValidUTF8(s, "\uFFFD"))
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMetricsTopZero(t *testing.T) {
+	res := scanResult{
+		root:     "/scan",
+		rootSize: 160,
+		files: Items{
+			{Path: "/scan/a/b/big", Size: 100, Allocated: 104},
+			{Path: "/scan/a/mid", Size: 50, Allocated: 52},
+			{Path: "/scan/small", Size: 10, Allocated: 12},
+		},
+		folders: Items{
+			{Path: "/scan/a", Size: 150, Allocated: 156, IsDir: true},
+			{Path: "/scan/a/b", Size: 100, Allocated: 104, IsDir: true},
+		},
+	}
+	var buf strings.Builder
+	if err := writeMetrics(&buf, res, 0, false, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nested folders only add to the count, so the others add up to a alone
+	out := buf.String()
+	for _, want := range []string{
+		`diskusage_folder_bytes{path="/scan",kind="other"} 150`,
+		`diskusage_folder_allocated_bytes{path="/scan",kind="other"} 156`,
+		`diskusage_folder_items{path="/scan",kind="other"} 2`,
+		`diskusage_file_bytes{path="/scan",kind="other"} 160`,
+		`diskusage_file_allocated_bytes{path="/scan",kind="other"} 168`,
+		`diskusage_file_items{path="/scan",kind="other"} 3`,
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Errorf("missing %s in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "/scan/") {
+		t.Errorf("exported a series of its own with -metrics-top 0:\n%s", out)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import "fmt"
+
+// mountInfo describes the filesystem mounted at mountPoint
+type mountInfo struct {
+	device     string
+	mountPoint string
+	fsType     string
+	label      string // filesystem label, "" when it has none
+	size       int64  // capacity in bytes
+	free       int64  // bytes available to unprivileged users
+	inodes     int64  // inode capacity, 0 when the filesystem allocates them dynamically
+	inodesFree int64  // inodes still available
+}
+
+// name returns the label, falling back to the device
+func (mi mountInfo) name() string {
+	if mi.label != "" {
+		return mi.label
+	}
+	return mi.device
+}
+
+// mountTitle describes the scanned volume for the title bar
+func (m model) mountTitle() string {
+	mi := m.mount
+	return fmt.Sprintf("- %s (%s) %s free of %s ", mi.name(), mi.fsType, m.formatSize(mi.free), m.formatSize(mi.size))
+}
+
+// defaultWarnAt is how full, in percent, the volume must be for the banner
+const defaultWarnAt = 90
+
+// fullBanner warns that the volume holding the scan, or the quota when one
+// is set, is at least -warn-at full. It returns "" when it isn't or the
+// volume's size is unknown.
+func (m model) fullBanner() string {
+	if q := m.opts.quotaBytes; q > 0 && m.opts.warnAt > 0 {
+		used := m.sizeOf(m.root)
+		if used*100/q < int64(m.opts.warnAt) {
+			return ""
+		}
+		return fmt.Sprintf(" Quota is %d%% used: %s of %s ", used*100/q, m.formatSize(used), m.formatSize(q))
+	}
+	v := m.volume
+	if m.opts.warnAt <= 0 || v == nil || v.size <= 0 {
+		return ""
+	}
+	used := (v.size - v.free) * 100 / v.size
+	if used < int64(m.opts.warnAt) {
+		return ""
+	}
+	return fmt.Sprintf(" Disk is %d%% full: %s free of %s on %s ", used, m.formatSize(v.free), m.formatSize(v.size), v.mountPoint)
+}
+
+// quotaTitle reports how much of the quota the scanned folder uses
+func (m model) quotaTitle() string {
+	used, q := m.sizeOf(m.root), m.opts.quotaBytes
+	return fmt.Sprintf("- using %s of %s quota (%d%%) ", m.formatSize(used), m.formatSize(q), used*100/q)
+}
+
+// inodeTitle reports the inodes used by the scan and, when the volume has a
+// fixed inode table, how full it is. A volume can run out of inodes while it
+// still has free space.
+func (m model) inodeTitle() string {
+	title := fmt.Sprintf("- %s inodes ", m.loc.comma(int64(m.root.Inodes)))
+	if mi := m.volume; mi != nil && mi.inodes > 0 {
+		used := mi.inodes - mi.inodesFree
+		title += fmt.Sprintf("(volume: %s of %s used, %d%%) ", m.loc.comma(used), m.loc.comma(mi.inodes), used*100/mi.inodes)
+	}
+	return title
+}
@@ -0,0 +1,69 @@
+//go:build darwin || freebsd
+
+package main
+
+import "syscall"
+
+// mntRdonly is MNT_RDONLY from sys/mount.h, the same value on both systems
+const mntRdonly = 0x1
+
+// readOnlyMount reports whether path lives on a filesystem mounted read-only
+func readOnlyMount(path string) bool {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return false
+	}
+	return st.Flags&mntRdonly != 0
+}
+
+// virtualFSTypes are the pseudo filesystems whose entries describe the
+// kernel or devices rather than stored data
+var virtualFSTypes = map[string]bool{
+	"devfs": true, "fdescfs": true, "procfs": true, "linprocfs": true, "linsysfs": true, "autofs": true,
+}
+
+// virtualFS reports whether path is on a pseudo filesystem such as /dev,
+// whose sizes are meaningless
+func virtualFS(path string) bool {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return false
+	}
+	return virtualFSTypes[cString(st.Fstypename[:])]
+}
+
+// resolveDevice returns path unchanged; pass the mount point instead of the
+// device node here
+func resolveDevice(path string) (string, error) {
+	return path, nil
+}
+
+// findMount returns the mount path is on, with its capacity. Labels aren't
+// looked up, so the device name is shown instead.
+func findMount(path string) (mountInfo, bool) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return mountInfo{}, false
+	}
+	return mountInfo{
+		device:     cString(st.Mntfromname[:]),
+		mountPoint: cString(st.Mntonname[:]),
+		fsType:     cString(st.Fstypename[:]),
+		size:       int64(st.Blocks) * int64(st.Bsize),
+		free:       int64(st.Bavail) * int64(st.Bsize),
+		inodes:     int64(st.Files),
+		inodesFree: int64(st.Ffree),
+	}, true
+}
+
+// cString converts a NUL-terminated statfs name field
+func cString(b []int8) string {
+	s := make([]byte, 0, len(b))
+	for _, c := range b {
+		if c == 0 {
+			break
+		}
+		s = append(s, byte(c))
+	}
+	return string(s)
+}
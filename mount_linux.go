@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// stRdonly is ST_RDONLY from statvfs(3), which the kernel also reports in
+// statfs f_flags
+const stRdonly = 0x1
+
+// readOnlyMount reports whether path lives on a filesystem mounted read-only
+func readOnlyMount(path string) bool {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return false
+	}
+	return st.Flags&stRdonly != 0
+}
+
+// virtualFSMagic holds the statfs f_type of pseudo filesystems whose entries
+// describe the kernel or devices rather than stored data, from
+// linux/magic.h
+var virtualFSMagic = map[int64]string{
+	0x9fa0:     "proc",
+	0x62656572: "sysfs",
+	0x1cd1:     "devpts",
+	0x27e0eb:   "cgroup",
+	0x63677270: "cgroup2",
+	0x64626720: "debugfs",
+	0x74726163: "tracefs",
+	0x73636673: "securityfs",
+	0x6165676c: "pstore",
+	0xcafe4a11: "bpf",
+	0x62656570: "configfs",
+	0x65735543: "fusectl",
+	0x19800202: "mqueue",
+	0x42494e4d: "binfmt_misc",
+	0xde5e81e4: "efivarfs",
+	0xf97cff8c: "selinuxfs",
+	0x6e736673: "nsfs",
+	0x0187:     "autofs",
+}
+
+// virtualFS reports whether path is on a pseudo filesystem such as /proc or
+// /sys, whose sizes are meaningless
+func virtualFS(path string) bool {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return false
+	}
+	_, ok := virtualFSMagic[int64(st.Type)]
+	return ok
+}
+
+// mounts lists /proc/mounts in order, later entries shadowing earlier ones
+func mounts() ([]mountInfo, error) {
+	f, err := os.Open("/proc/mounts")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []mountInfo
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 3 {
+			continue
+		}
+		out = append(out, mountInfo{
+			device:     unescapeMount(fields[0]),
+			mountPoint: unescapeMount(fields[1]),
+			fsType:     fields[2],
+		})
+	}
+	return out, sc.Err()
+}
+
+// unescapeMount decodes the octal escapes (\040 for a space) used in
+// /proc/mounts fields
+func unescapeMount(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// resolveDevice maps a block device to the directory it's mounted on, so
+// `diskusage /dev/sdb1` scans the drive. Other paths are returned unchanged.
+func resolveDevice(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil || info.Mode()&os.ModeDevice == 0 {
+		return path, nil
+	}
+	dev, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	list, err := mounts()
+	if err != nil {
+		return "", err
+	}
+	for _, mi := range list {
+		if real, err := filepath.EvalSymlinks(mi.device); err == nil && real == dev {
+			return mi.mountPoint, nil
+		}
+	}
+	return "", fmt.Errorf("%s is not mounted", path)
+}
+
+// findMount returns the mount path is on, with its label and capacity
+func findMount(path string) (mountInfo, bool) {
+	list, err := mounts()
+	if err != nil {
+		return mountInfo{}, false
+	}
+	var best mountInfo
+	found := false
+	for _, mi := range list {
+		if isWithin(path, mi.mountPoint) && (!found || len(mi.mountPoint) >= len(best.mountPoint)) {
+			best, found = mi, true
+		}
+	}
+	if !found {
+		return mountInfo{}, false
+	}
+
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(best.mountPoint, &st); err == nil {
+		best.size = int64(st.Blocks) * int64(st.Bsize)
+		best.free = int64(st.Bavail) * int64(st.Bsize)
+		best.inodes, best.inodesFree = int64(st.Files), int64(st.Ffree)
+	}
+	best.label = deviceLabel(best.device)
+	return best, true
+}
+
+// isWithin reports whether path is dir or below it
+func isWithin(path, dir string) bool {
+	if dir == "/" || path == dir {
+		return true
+	}
+	return strings.HasPrefix(path, dir+"/")
+}
+
+// deviceLabel looks the device up among udev's /dev/disk/by-label links
+func deviceLabel(device string) string {
+	dev, err := filepath.EvalSymlinks(device)
+	if err != nil {
+		return ""
+	}
+	entries, err := os.ReadDir("/dev/disk/by-label")
+	if err != nil {
+		return ""
+	}
+	for _, e := range entries {
+		target, err := filepath.EvalSymlinks(filepath.Join("/dev/disk/by-label", e.Name()))
+		if err == nil && target == dev {
+			// udev hex-escapes spaces in link names
+			return strings.ReplaceAll(e.Name(), `\x20`, " ")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,23 @@
+//go:build !linux && !darwin && !freebsd
+
+package main
+
+// readOnlyMount always reports false where statfs isn't available; deletions
+// there fail individually instead
+func readOnlyMount(path string) bool {
+	return false
+}
+
+// virtualFS can't tell filesystem types apart here, so nothing is skipped
+func virtualFS(path string) bool {
+	return false
+}
+
+func resolveDevice(path string) (string, error) {
+	return path, nil
+}
+
+// findMount isn't supported here, so no volume details are shown
+func findMount(path string) (mountInfo, bool) {
+	return mountInfo{}, false
+}
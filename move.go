@@ -0,0 +1,251 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// transfer is a move or copy of the selection waiting for confirmation
+type transfer struct {
+	dest string
+	copy bool
+}
+
+func (t transfer) verb() string {
+	if t.copy {
+		return "Copy"
+	}
+	return "Move"
+}
+
+// moveDoneMsg reports the outcome of moving the selection
+type moveDoneMsg struct {
+	dest   string
+	moved  []string // source paths that were moved
+	failed []scanError
+}
+
+// promptTransfer asks where the selected items should be moved or copied,
+// then hands over to the usual grouped confirmation
+func (m model) promptTransfer(copy bool) model {
+	// Copying leaves the source alone, so it works on read-only mounts too
+	if reason := m.deletionDisabled(); reason != "" && !(copy && m.readOnlyFS) {
+		m.status = reason
+		return m
+	}
+	if m.progress != nil {
+		m.status = "Wait for the current copy to finish"
+		return m
+	}
+	if m.deleting != nil {
+		m.status = "Wait for the current deletion to finish"
+		return m
+	}
+	n := len(m.selectedItems())
+	if n == 0 {
+		m.status = m.nothingSelected()
+		return m
+	}
+	t := transfer{copy: copy}
+	m.prompt = &prompt{
+		label: fmt.Sprintf("%s %d items to: ", t.verb(), n),
+		onSubmit: func(m model, value string) (model, tea.Cmd) {
+			if value == "" {
+				return m, nil
+			}
+			dest, err := filepath.Abs(value)
+			if err == nil {
+				var info os.FileInfo
+				if info, err = os.Stat(dest); err == nil && !info.IsDir() {
+					err = fmt.Errorf("%s is not a directory", dest)
+				}
+			}
+			if err == nil {
+				err = m.insideSelection(dest)
+			}
+			if err != nil {
+				m.status = "Can't " + strings.ToLower(t.verb()) + " there: " + err.Error()
+				return m, nil
+			}
+			t.dest = dest
+			m.transfer, m.confirming = &t, true
+			return m, nil
+		},
+	}
+	return m
+}
+
+// insideSelection refuses a destination that is one of the selected folders
+// or lies below one, where a copy would keep copying itself
+func (m model) insideSelection(dest string) error {
+	// Scanned paths are real paths, so links to a selected folder count too
+	real, err := filepath.EvalSymlinks(dest)
+	if err != nil {
+		return err
+	}
+	for _, item := range m.outerSelected() {
+		if item.IsDir && (real == item.Path || strings.HasPrefix(real, item.Path+string(filepath.Separator))) {
+			return fmt.Errorf("%s is inside the selected %s", dest, item.Path)
+		}
+	}
+	return nil
+}
+
+// moveSelected moves the selection into dest in the background. Items inside
+// a selected folder move along with it.
+func (m model) moveSelected(dest string) tea.Cmd {
+	items := m.outerSelected()
+	return func() tea.Msg {
+		msg := moveDoneMsg{dest: dest}
+		for _, item := range items {
+			if err := moveItem(item.Path, dest); err != nil {
+				msg.failed = append(msg.failed, scanError{path: item.Path, err: fmt.Errorf("move: %w", err)})
+				continue
+			}
+			msg.moved = append(msg.moved, item.Path)
+		}
+		return msg
+	}
+}
+
+// finishMove deselects what was moved, along with anything selected inside
+// a moved folder, and logs what wasn't
+func (m model) finishMove(msg moveDoneMsg) model {
+	moved := make(map[string]bool, len(msg.moved))
+	for _, p := range msg.moved {
+		moved[p] = true
+	}
+	for _, item := range m.selectedItems() {
+		if moved[item.Path] || selectedAncestor(item.Path, moved) {
+			m = m.setSelected(item.Path, false)
+		}
+	}
+
+	m.status = fmt.Sprintf("Moved %d items to %s", len(msg.moved), msg.dest)
+	if len(msg.failed) > 0 {
+		m.scanErrors = append(m.scanErrors, msg.failed...)
+		m.status += fmt.Sprintf(", %d failed - press %s for details", len(msg.failed), m.keys.short(actErrors))
+	}
+	return m
+}
+
+// moveItem moves src into the directory dest, keeping its name. An existing
+// entry of that name is never overwritten. Across filesystems, where rename
+// can't work, src is copied and then removed.
+func moveItem(src, dest string) error {
+	target := filepath.Join(dest, filepath.Base(src))
+	if _, err := os.Lstat(target); err == nil {
+		return fmt.Errorf("%s already exists", target)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	err := os.Rename(src, target)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := copyItem(src, target, nil); err != nil {
+		return fmt.Errorf("copying to another filesystem: %w", err)
+	}
+	return os.RemoveAll(src)
+}
+
+// copyItem copies src to dst, which must not exist yet, reporting the bytes
+// written as it goes when progress isn't nil. A failed copy is removed
+// again so no partial tree is left behind.
+func copyItem(src, dst string, progress func(n int64)) error {
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("%s already exists", dst)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if progress == nil {
+		progress = func(int64) {}
+	}
+	if err := copyPath(src, dst, progress); err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+	return nil
+}
+
+// copyPath copies a file, symlink or directory tree, preserving permissions
+// and modification times
+func copyPath(src, dst string, progress func(n int64)) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		link, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		// Chtimes would follow the link, so it keeps the time it's made at
+		return os.Symlink(link, dst)
+	case info.IsDir():
+		// Stay writable until the children are in, a read-only source
+		// folder gets its own mode back afterwards
+		if err := os.Mkdir(dst, 0o700); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := copyPath(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name()), progress); err != nil {
+				return err
+			}
+		}
+		if err := os.Chmod(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+	case info.Mode().IsRegular():
+		if err := copyFile(src, dst, info.Mode().Perm(), progress); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%s: can't copy special files", src)
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+func copyFile(src, dst string, perm os.FileMode, progress func(n int64)) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(progressWriter{out, progress}, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// progressWriter reports every successful write to progress
+type progressWriter struct {
+	w        io.Writer
+	progress func(n int64)
+}
+
+func (pw progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	pw.progress(int64(n))
+	return n, err
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// navFrame remembers where the user was before drilling into a folder
+type navFrame struct {
+	scope    string
+	viewMode string
+	cursor   int
+	offset   int
+}
+
+// dir returns the directory currently being browsed
+func (m model) dir() string {
+	if m.scope == "" {
+		return m.basePath
+	}
+	return m.scope
+}
+
+// inScope reports whether item lives below the folder drilled into. The root
+// summary row only belongs to the top level.
+func (m model) inScope(item Item) bool {
+	if m.scope == "" {
+		return true
+	}
+	return !item.IsRoot && strings.HasPrefix(item.Path, m.scope+string(filepath.Separator))
+}
+
+// drillDown narrows both lists to the contents of the folder under the cursor
+func (m model) drillDown() model {
+	item, ok := m.currentItem()
+	if !ok || !item.IsDir {
+		return m
+	}
+	if item.IsRoot || item.Change == changeRemoved {
+		m.status = "Can't open " + filepath.Base(item.Path)
+		return m
+	}
+	m.navStack = append(m.navStack, navFrame{scope: m.scope, viewMode: m.viewMode, cursor: m.cursor, offset: m.offset})
+	m.scope = item.Path
+	m.cursor, m.offset = 0, 0
+	return m.clampView()
+}
+
+// goBack returns to the folder the current one was opened from
+func (m model) goBack() model {
+	if len(m.navStack) == 0 {
+		return m
+	}
+	return m.restore(m.navStack[len(m.navStack)-1], m.navStack[:len(m.navStack)-1])
+}
+
+// goHome pops the whole navigation stack, back to the scanned directory
+func (m model) goHome() model {
+	if len(m.navStack) == 0 {
+		return m
+	}
+	return m.restore(m.navStack[0], nil)
+}
+
+// jumpToLargest opens the folder holding the biggest file of the whole scan
+// in the combined view, with the cursor on the file, as a starting point for
+// cleaning up. Back returns to where the jump was made from.
+func (m model) jumpToLargest() model {
+	var largest Item
+	for _, f := range m.files {
+		if f.deletable() && (largest.Path == "" || m.sizeOf(f) > m.sizeOf(largest)) {
+			largest = f
+		}
+	}
+	if largest.Path == "" {
+		m.status = "No files to jump to"
+		return m
+	}
+
+	m.navStack = append(m.navStack, navFrame{scope: m.scope, viewMode: m.viewMode, cursor: m.cursor, offset: m.offset})
+	m.scope, m.viewMode = filepath.Dir(largest.Path), "combined"
+	if m.scope == m.basePath {
+		m.scope = ""
+	}
+	m.cursor, m.offset = 0, 0
+	var found bool
+	if m, found = m.cursorTo(largest.Path); !found {
+		m, _ = m.setFilter("").cursorTo(largest.Path)
+	}
+	m.status = fmt.Sprintf("Largest file: %s (%s)", getRelativePath(largest.Path, m.basePath), m.formatSize(m.sizeOf(largest)))
+	return m.clampView()
+}
+
+// cursorTo moves the cursor to the row listing path, reporting false if no
+// row in the current view does
+func (m model) cursorTo(path string) (model, bool) {
+	rows := m.rows()
+	for row := 0; row < rows.len(); row++ {
+		if rows.at(row).Path == path {
+			m.cursor = row
+			return m, true
+		}
+	}
+	return m, false
+}
+
+func (m model) restore(f navFrame, stack []navFrame) model {
+	m.navStack = stack
+	m.scope, m.viewMode = f.scope, f.viewMode
+	m.cursor, m.offset = f.cursor, f.offset
+	return m.clampView()
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// ndjsonRecord is one line of an NDJSON listing. The stream holds a single
+// "root" record plus one "file" or "dir" record per item.
+type ndjsonRecord struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+
+	// Allocated is optional; listings without it treat it as Size. It's a
+	// pointer so a file with nothing allocated, such as a sparse one, reads
+	// back as 0 rather than as its size.
+	Allocated *int64 `json:"allocated,omitempty"`
+	Count     int    `json:"count,omitempty"`
+	Inodes    int    `json:"inodes,omitempty"`
+	Mount     bool   `json:"mount,omitempty"`
+	Partial   bool   `json:"partial,omitempty"`
+
+	// ScanSeconds is how long the scan took, on the root record with -timing
+	ScanSeconds float64 `json:"scanSeconds,omitempty"`
+}
+
+// writeNDJSON streams res to w, one JSON object per line, with paths passed
+// through anon
+func writeNDJSON(w io.Writer, res scanResult, anon *anonymizer) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	if err := enc.Encode(ndjsonRecord{Type: "root", Path: anon.path(res.root), Size: res.rootSize, Allocated: &res.rootAlloc, Count: res.rootCount, Inodes: res.rootInodes, Partial: res.rootPartial, ScanSeconds: res.elapsed.Seconds()}); err != nil {
+		return err
+	}
+	for _, item := range res.folders {
+		if err := enc.Encode(ndjsonRecord{Type: "dir", Path: anon.path(item.Path), Size: item.Size, Allocated: &item.Allocated, Count: item.ItemCount, Inodes: item.Inodes, Mount: item.IsMount, Partial: item.SizePartial}); err != nil {
+			return err
+		}
+	}
+	for _, item := range res.files {
+		if err := enc.Encode(ndjsonRecord{Type: "file", Path: anon.path(item.Path), Size: item.Size, Allocated: &item.Allocated}); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// readNDJSON decodes a listing written by writeNDJSON. Records are decoded
+// one at a time so the raw input is never held in memory.
+func readNDJSON(r io.Reader) (scanResult, error) {
+	var res scanResult
+	dec := json.NewDecoder(bufio.NewReader(r))
+
+	for line := 1; ; line++ {
+		var rec ndjsonRecord
+		err := dec.Decode(&rec)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return res, fmt.Errorf("record %d: %w", line, err)
+		}
+
+		allocated := rec.Size
+		if rec.Allocated != nil {
+			allocated = *rec.Allocated
+		}
+
+		switch rec.Type {
+		case "root":
+			res.root = rec.Path
+			res.rootSize, res.rootAlloc, res.rootCount, res.rootInodes = rec.Size, allocated, rec.Count, rec.Inodes
+			res.rootPartial = rec.Partial
+			res.elapsed = time.Duration(rec.ScanSeconds * float64(time.Second))
+		case "dir":
+			res.folders = append(res.folders, Item{Path: rec.Path, Size: rec.Size, Allocated: allocated, ItemCount: rec.Count, Inodes: rec.Inodes, IsDir: true, IsMount: rec.Mount, SizePartial: rec.Partial})
+		case "file":
+			res.files = append(res.files, Item{Path: rec.Path, Size: rec.Size, Allocated: allocated})
+			res.total += rec.Size
+			res.totalAlloc += allocated
+		default:
+			return res, fmt.Errorf("record %d: unknown type %q", line, rec.Type)
+		}
+	}
+
+	if res.root == "" {
+		return res, errors.New("listing has no root record")
+	}
+
+	sort.Sort(res.files)
+	sort.Sort(res.folders)
+
+	return res, nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNDJSONAllocated(t *testing.T) {
+	res := scanResult{root: "/scan", rootSize: 110, rootAlloc: 4096, files: Items{
+		{Path: "/scan/sparse", Size: 100, Allocated: 0},
+		{Path: "/scan/small", Size: 10, Allocated: 4096},
+	}}
+	var buf bytes.Buffer
+	if err := writeNDJSON(&buf, res, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Listings from before allocated sizes were exported fall back to the size
+	old := `{"type":"file","path":"/scan/old","size":7}` + "\n"
+	got, err := readNDJSON(strings.NewReader(buf.String() + old))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int64{"/scan/sparse": 0, "/scan/small": 4096, "/scan/old": 7}
+	for _, f := range got.files {
+		if f.Allocated != want[f.Path] {
+			t.Errorf("%s allocated %d, want %d", f.Path, f.Allocated, want[f.Path])
+		}
+	}
+	if len(got.files) != len(want) || got.rootAlloc != 4096 {
+		t.Errorf("read %d files with root allocated %d, want %d and 4096", len(got.files), got.rootAlloc, len(want))
+	}
+}
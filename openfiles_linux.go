@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// openFiles maps the path of every file held open by a running process to
+// the names of the processes holding it, read from /proc/*/fd. Without
+// root, other users' processes can't be inspected, so their files are
+// missed.
+func openFiles() map[string][]string {
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	open := map[string][]string{}
+	for _, p := range procs {
+		if _, err := strconv.Atoi(p.Name()); err != nil {
+			continue
+		}
+		dir := filepath.Join("/proc", p.Name())
+		fds, err := os.ReadDir(filepath.Join(dir, "fd"))
+		if err != nil {
+			continue
+		}
+		comm, _ := os.ReadFile(filepath.Join(dir, "comm"))
+		name := strings.TrimSpace(string(comm))
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(dir, "fd", fd.Name()))
+			if err != nil || !filepath.IsAbs(target) {
+				continue // sockets, pipes and anonymous inodes
+			}
+			if names := open[target]; len(names) == 0 || names[len(names)-1] != name {
+				open[target] = append(names, name)
+			}
+		}
+	}
+	return open
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// defaultParetoPercent is the share of the total -pareto keeps by default
+const defaultParetoPercent = 90
+
+// paretoRest sums up the items the Pareto view collapses
+type paretoRest struct {
+	count int
+	size  int64
+}
+
+// pareto narrows v to the largest items that together reach the chosen
+// share of its total, keeping their current order. In the folders view only
+// the outermost folders count, as their subfolders are already part of
+// their sizes. The root summary row is always kept.
+func (m model) pareto(v rowView) rowView {
+	visible := make(map[string]bool, v.len())
+	for row := 0; row < v.len(); row++ {
+		visible[v.at(row).Path] = true
+	}
+
+	var candidates []int
+	var total int64
+	for row := 0; row < v.len(); row++ {
+		item := v.at(row)
+		if item.IsRoot {
+			continue
+		}
+		if _, nested := m.visibleParent(item.Path, visible); nested && m.viewMode == "folders" {
+			continue
+		}
+		candidates = append(candidates, row)
+		total += m.sizeOf(*item)
+	}
+
+	largest := append([]int(nil), candidates...)
+	sort.SliceStable(largest, func(i, j int) bool {
+		return m.sizeOf(*v.at(largest[i])) > m.sizeOf(*v.at(largest[j]))
+	})
+	limit := total * int64(m.opts.paretoPercent) / 100
+	keep := map[int]bool{}
+	var sum int64
+	for _, row := range largest {
+		if sum >= limit && len(keep) > 0 {
+			break
+		}
+		keep[row] = true
+		sum += m.sizeOf(*v.at(row))
+	}
+
+	idx := []int{}
+	for row := 0; row < v.len(); row++ {
+		if keep[row] || v.at(row).IsRoot {
+			idx = append(idx, v.index(row))
+		}
+	}
+	rest := paretoRest{count: len(candidates) - len(keep), size: total - sum}
+	return rowView{items: v.items, idx: idx, rest: rest}
+}
+
+// paretoRestView is the row standing in for the collapsed items
+func (m model) paretoRestView(rest paretoRest) string {
+	noun := "items"
+	if rest.count == 1 {
+		noun = "item"
+	}
+	return m.styles.helpText.Render(fmt.Sprintf("    ... and %d smaller %s (%s)", rest.count, noun, m.formatSize(rest.size)))
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxPieSlices is how many children get their own slice; smaller ones are
+// merged into "everything else"
+const maxPieSlices = 7
+
+// pieGlyphs fill each slice, so slices stay apart even without colors. The
+// last one is "everything else".
+var pieGlyphs = []rune("█▓▒░▞▚▤#")
+
+// pieColors tint the slices in the same order as pieGlyphs
+var pieColors = []lipgloss.CompleteColor{colorSize, colorGrowth, colorHighlight, colorError, colorPurple, colorCyan, colorPink, colorMuted}
+
+// pieSlice is one child of the current folder, or the rest merged
+type pieSlice struct {
+	name string
+	size int64
+}
+
+// pieSlices lists the immediate children of the folder being browsed by
+// size, merging those after the largest maxPieSlices
+func (m model) pieSlices() ([]pieSlice, int64) {
+	var slices []pieSlice
+	var total int64
+	for _, item := range m.combined {
+		if item.IsRoot || !m.topLevel(item) {
+			continue
+		}
+		name := printable(filepath.Base(item.Path))
+		if item.IsDir && m.opts.dirSlash {
+			name += string(filepath.Separator)
+		}
+		size := m.sizeOf(item)
+		slices = append(slices, pieSlice{name, size})
+		total += size
+	}
+	sort.SliceStable(slices, func(i, j int) bool { return slices[i].size > slices[j].size })
+	if len(slices) > maxPieSlices {
+		rest := pieSlice{name: fmt.Sprintf("everything else (%d items)", len(slices)-maxPieSlices)}
+		for _, s := range slices[maxPieSlices:] {
+			rest.size += s.size
+		}
+		slices = append(slices[:maxPieSlices], rest)
+	}
+	return slices, total
+}
+
+// pieView renders the full-screen donut chart of the current folder's
+// immediate children with a legend of their shares
+func (m model) pieView() string {
+	slices, total := m.pieSlices()
+
+	var s strings.Builder
+	s.WriteString(m.styles.title.Render(" Disk Usage Analyzer - BREAKDOWN ") + "\n\n")
+	s.WriteString(m.styles.normal.Render(fmt.Sprintf("%s - %s", printable(m.dir()), m.formatSize(total))) + "\n\n")
+	if total == 0 {
+		s.WriteString(m.styles.normal.Render("Nothing here takes up space") + "\n")
+	} else {
+		legend := make([]string, len(slices))
+		for i, sl := range slices {
+			share := float64(sl.size) / float64(total) * 100
+			legend[i] = m.pieStyle(i).Render(strings.Repeat(string(pieGlyphs[i]), 2)) +
+				m.styles.normal.Render(fmt.Sprintf(" %5.1f%% %s ", share, padLeft(m.formatSize(sl.size), m.sizeWidth()))) +
+				m.styles.normal.Render(truncateString(sl.name, max(m.width/2-m.sizeWidth()-12, 10)))
+		}
+		chart := m.donut(slices, total)
+		s.WriteString(lipgloss.JoinHorizontal(lipgloss.Center, chart, "    ", strings.Join(legend, "\n")) + "\n")
+	}
+	s.WriteString(m.styles.helpText.Render(fmt.Sprintf("\n%s/Esc: Close", m.keys.short(actPie))))
+
+	block := lipgloss.JoinVertical(lipgloss.Left, strings.Split(s.String(), "\n")...)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, block)
+}
+
+// donut draws the slices as a ring, clockwise from the top. Terminal cells
+// are about twice as tall as wide, so the ring is twice as wide as tall.
+func (m model) donut(slices []pieSlice, total int64) string {
+	radius := min(min((m.height-8)/2, m.width/8), 10)
+	if radius < 2 {
+		return ""
+	}
+	ends := make([]float64, len(slices))
+	cum := 0.0
+	for i, sl := range slices {
+		cum += float64(sl.size) / float64(total)
+		ends[i] = cum
+	}
+
+	lines := make([]string, 2*radius)
+	for y := range lines {
+		var line strings.Builder
+		for x := 0; x < 4*radius; x++ {
+			dx := (float64(x) + 0.5 - float64(2*radius)) / float64(2*radius)
+			dy := (float64(y) + 0.5 - float64(radius)) / float64(radius)
+			if d := math.Hypot(dx, dy); d > 1 || d < 0.5 {
+				line.WriteByte(' ')
+				continue
+			}
+			// Fraction of a turn, clockwise from 12 o'clock
+			turn := math.Atan2(dx, -dy) / (2 * math.Pi)
+			if turn < 0 {
+				turn++
+			}
+			i := sort.SearchFloat64s(ends, turn)
+			i = min(i, len(slices)-1)
+			line.WriteString(m.pieStyle(i).Render(string(pieGlyphs[i])))
+		}
+		lines[y] = line.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// pieStyle colors slice i
+func (m model) pieStyle(i int) lipgloss.Style {
+	return m.styles.normal.Foreground(pieColors[i])
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// progressiveInterval limits how often folder sizes arriving from a
+// -progressive scan re-sort the lists
+const progressiveInterval = 250 * time.Millisecond
+
+// folderSizesMsg delivers the folders a -progressive scan has sized since
+// the previous message. The last one carries the finished scan instead. ch
+// delivers the next message.
+type folderSizesMsg struct {
+	sized Items
+	final *scanResult
+	ch    <-chan tea.Msg
+}
+
+// startProgressive lists the tree under path and returns a model showing it
+// right away, with the folders the cache doesn't know marked as being
+// calculated while they are sized in the background
+func startProgressive(path string, opts options) (model, error) {
+	root, err := scanRoot(path)
+	if err != nil {
+		return model{}, err
+	}
+	p := listTree(root, opts.scan)
+	if p.err != nil {
+		return model{}, p.err
+	}
+	m, err := newModel(p.preview(), opts)
+	if err != nil {
+		return model{}, err
+	}
+
+	ch := make(chan tea.Msg, 1)
+	go func() {
+		var batch Items
+		last := time.Now()
+		res, _ := p.finish(func(dir Item) {
+			batch = append(batch, dir)
+			if time.Since(last) < progressiveInterval {
+				return
+			}
+			select {
+			case ch <- folderSizesMsg{sized: batch, ch: ch}:
+				batch, last = nil, time.Now()
+			default:
+			}
+		})
+		ch <- folderSizesMsg{final: &res, ch: ch}
+	}()
+	m.sizing = &folderSizesMsg{ch: ch}
+	return m, nil
+}
+
+// applySizes fills in folder sizes as they arrive and re-sorts, keeping the
+// cursor on the same item. Once the scan finishes, folders that couldn't be
+// sized are dropped, as a regular scan leaves them out, and their errors
+// are added to the log. Messages from a scan since replaced are ignored.
+func (m model) applySizes(msg folderSizesMsg) model {
+	if m.sizing == nil || m.sizing.ch != msg.ch {
+		return m
+	}
+	current, hadCurrent := m.currentItem()
+
+	sized := msg.sized
+	if msg.final != nil {
+		sized = msg.final.folders
+	}
+	byPath := make(map[string]Item, len(sized))
+	for _, dir := range sized {
+		byPath[dir.Path] = dir
+	}
+	if root, ok := byPath[m.basePath]; ok {
+		m.root.Size, m.root.Allocated, m.root.ItemCount, m.root.Inodes = root.Size, root.Allocated, root.ItemCount, root.Inodes
+		m.root.SizePartial, m.root.LargestChild, m.root.SizePending = root.SizePartial, root.LargestChild, false
+	}
+	if res := msg.final; res != nil {
+		m.root.Size, m.root.Allocated, m.root.ItemCount, m.root.Inodes = res.rootSize, res.rootAlloc, res.rootCount, res.rootInodes
+		m.root.SizePartial, m.root.SizePending = res.rootPartial, false
+		m.scanErrors = res.errors
+		m.sizing = nil
+		m.status = "All folder sizes calculated"
+		if m.opts.timing {
+			m.status += ": " + m.scanStats(*res)
+		}
+		m.scanTime = res.elapsed
+	}
+
+	update := func(items Items) Items {
+		kept := items[:0]
+		for _, item := range items {
+			switch dir, ok := byPath[item.Path]; {
+			case item.IsRoot:
+				item = m.root
+			case ok && item.IsDir:
+				item = dir
+			case item.SizePending && msg.final != nil:
+				continue
+			}
+			kept = append(kept, item)
+		}
+		return kept
+	}
+	m.folders, m.combined = update(m.folders), update(m.combined)
+
+	m = m.rebase().setSort(m.sortKey, m.sortDesc)
+	if hadCurrent {
+		m, _ = m.cursorTo(current.Path)
+	}
+	return m.clampView()
+}
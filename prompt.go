@@ -0,0 +1,55 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// prompt is a single-line text input shown in place of the status line
+type prompt struct {
+	label    string
+	input    string
+	onSubmit func(m model, value string) (model, tea.Cmd)
+	onChange func(m model, value string) model  // optional, called on every edit
+	onCancel func(m model) model                // optional, called on Esc
+	complete func(m model, value string) string // optional, called on Tab
+}
+
+// updatePrompt feeds a key press into the active prompt. Enter submits the
+// input, Esc cancels it and Tab completes it where the prompt supports that.
+func (m model) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := *m.prompt
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.prompt = nil
+		if p.onCancel != nil {
+			m = p.onCancel(m)
+		}
+		return m, nil
+	case tea.KeyEnter:
+		m.prompt = nil
+		return p.onSubmit(m, p.input)
+	case tea.KeyBackspace:
+		if r := []rune(p.input); len(r) > 0 {
+			p.input = string(r[:len(r)-1])
+		}
+	case tea.KeyTab:
+		if p.complete != nil {
+			p.input = p.complete(m, p.input)
+		}
+	case tea.KeySpace:
+		p.input += " "
+	case tea.KeyRunes:
+		p.input += string(msg.Runes)
+	}
+	if p.onChange != nil && p.input != m.prompt.input {
+		m = p.onChange(m, p.input)
+	}
+	m.prompt = &p
+	return m, nil
+}
+
+func (p prompt) view(st styles) string {
+	return st.confirmText.Render(p.label) + " " + st.normal.Render(p.input+"█")
+}
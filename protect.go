@@ -0,0 +1,95 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// parseProtected normalizes a -protect list such as "go,.DOCX, psd" to
+// lower-case extensions with their leading dot
+func parseProtected(spec string) []string {
+	var exts []string
+	for _, ext := range strings.Split(spec, ",") {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if !slices.Contains(exts, ext) {
+			exts = append(exts, ext)
+		}
+	}
+	return exts
+}
+
+// protectedExt returns the -protect extension a file name ends with, the
+// longest one when several do, so ".tar.gz" can be protected apart from ".gz"
+func (o options) protectedExt(path string) (string, bool) {
+	name := strings.ToLower(filepath.Base(path))
+	found := ""
+	for _, ext := range o.protected {
+		if strings.HasSuffix(name, ext) && len(ext) > len(found) {
+			found = ext
+		}
+	}
+	return found, found != ""
+}
+
+// protectedSelected counts the files with protected extensions a delete
+// would remove, by extension, including those inside selected folders
+func (m model) protectedSelected() map[string]int {
+	if len(m.opts.protected) == 0 {
+		return nil
+	}
+	counts := map[string]int{}
+	seen := map[string]bool{}
+	count := func(path string) {
+		if ext, ok := m.opts.protectedExt(path); ok && !seen[path] {
+			seen[path] = true
+			counts[ext]++
+		}
+	}
+	for _, item := range m.selectedItems() {
+		if !item.IsDir {
+			count(item.Path)
+			continue
+		}
+		for _, f := range m.files {
+			if strings.HasPrefix(f.Path, item.Path+string(filepath.Separator)) {
+				count(f.Path)
+			}
+		}
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+	return counts
+}
+
+// protectedWarning calls out the protected files in the confirmation, most
+// common extension first, such as "12 .go, 1 .docx"
+func (m model) protectedWarning() string {
+	exts := make([]string, 0, len(m.protected))
+	total := 0
+	for ext, n := range m.protected {
+		exts = append(exts, ext)
+		total += n
+	}
+	slices.SortFunc(exts, func(a, b string) int {
+		return cmp.Or(cmp.Compare(m.protected[b], m.protected[a]), cmp.Compare(a, b))
+	})
+	parts := make([]string, len(exts))
+	for i, ext := range exts {
+		parts[i] = fmt.Sprintf("%d %s", m.protected[ext], ext)
+	}
+	noun := "files"
+	if total == 1 {
+		noun = "file"
+	}
+	return fmt.Sprintf("Protected: this deletes %d %s with protected extensions (%s)", total, noun, strings.Join(parts, ", "))
+}
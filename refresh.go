@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// refreshMsg carries a rescan of the root started because the terminal
+// regained focus
+type refreshMsg struct {
+	res scanResult
+	err error
+}
+
+// refreshOnFocus rescans the root in the background when -refresh-on-focus
+// is set, unless something is in progress that a new listing would disturb
+func (m model) refreshOnFocus() (model, tea.Cmd) {
+	if !m.opts.refreshOnFocus || m.refreshing || m.sizing != nil || m.gone || m.confirming || m.prompt != nil || m.progress != nil || m.deleting != nil || m.hashing != nil {
+		return m, nil
+	}
+	m.refreshing = true
+	m.status = "Refreshing " + m.basePath + "..."
+	m.scanning = &scanProgressMsg{}
+	return m, backgroundScan(m.basePath, m.opts, func(res scanResult, err error) tea.Msg {
+		return refreshMsg{res: res, err: err}
+	})
+}
+
+// applyRefresh swaps in the refreshed listing while keeping the folder
+// being browsed, the filter and the item under the cursor where they still
+// exist
+func (m model) applyRefresh(msg refreshMsg) model {
+	m.refreshing, m.scanning = false, nil
+	if msg.err != nil {
+		m.status = "Can't refresh: " + msg.err.Error()
+		return m
+	}
+	if msg.res.root != m.basePath {
+		return m // another folder was scanned in the meantime
+	}
+
+	scope, navStack, filter, offset := m.scope, m.navStack, m.filter, m.offset
+	current, hadCurrent := m.currentItem()
+	m = m.withScan(msg.res)
+	if scope != "" {
+		if _, err := os.Stat(scope); err == nil {
+			m.scope, m.navStack = scope, navStack
+		}
+	}
+	m.filter, m.offset = filter, offset
+
+	if hadCurrent {
+		m, _ = m.cursorTo(current.Path)
+	}
+	m.status = "Refreshed " + m.basePath
+	if m.opts.timing {
+		m.status += ": " + m.scanStats(msg.res)
+	}
+	return m.clampView()
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// deletion records an item removed during the session, for the report and
+// the deletion log
+type deletion struct {
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	Allocated int64     `json:"allocated"`
+	Time      time.Time `json:"time"`
+	Method    string    `json:"method"` // removedPermanent, removedSecure or removedSudo
+}
+
+// reportItem is one listed item in a report
+type reportItem struct {
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Allocated int64  `json:"allocated"`
+}
+
+// report is what Q saves before quitting: the current view, its totals and
+// everything deleted this session
+type report struct {
+	Root    string       `json:"root"`
+	Created time.Time    `json:"created"`
+	View    string       `json:"view"`
+	Filter  string       `json:"filter,omitempty"`
+	Scope   string       `json:"scope,omitempty"`
+	Total   int64        `json:"total"` // size of the listed items, as shown in the title
+	Items   []reportItem `json:"items"`
+	Deleted []deletion   `json:"deleted"`
+
+	// ScanSeconds is how long the scan took, with -timing
+	ScanSeconds float64 `json:"scanSeconds,omitempty"`
+}
+
+// newReport captures the current view, with paths anonymized when asked
+func (m model) newReport() report {
+	var anon *anonymizer
+	if m.opts.anonymize {
+		anon = newAnonymizer()
+	}
+	var scanSeconds float64
+	if m.opts.timing {
+		scanSeconds = m.scanTime.Seconds()
+	}
+	r := report{
+		Root:    anon.path(m.basePath),
+		Created: time.Now(),
+		View:    m.viewMode,
+		Filter:  m.filter,
+		Scope:   anon.path(m.scope),
+		Total:   m.viewTotal(),
+		Items:   []reportItem{},
+		Deleted: []deletion{},
+
+		ScanSeconds: scanSeconds,
+	}
+	for _, d := range m.deleted {
+		d.Path = anon.path(d.Path)
+		r.Deleted = append(r.Deleted, d)
+	}
+	rows := m.rows()
+	for row := range rows.len() {
+		item := rows.at(row)
+		if item.IsLoose {
+			continue
+		}
+		r.Items = append(r.Items, reportItem{Path: anon.path(item.Path), Size: item.Size, Allocated: item.Allocated})
+	}
+	return r
+}
+
+// writeJSON writes the report as a single indented JSON document
+func (r report) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// writeCSV writes the report as rows of type,path,size,allocated,time. The
+// "root" row carries the report's timestamp and "total" the view total.
+func (r report) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	size := func(n int64) string { return strconv.FormatInt(n, 10) }
+	cw.Write([]string{"type", "path", "size", "allocated", "time"})
+	cw.Write([]string{"root", r.Root, "", "", r.Created.Format(time.RFC3339)})
+	cw.Write([]string{"total", r.View, size(r.Total), "", ""})
+	for _, item := range r.Items {
+		cw.Write([]string{"item", item.Path, size(item.Size), size(item.Allocated), ""})
+	}
+	for _, d := range r.Deleted {
+		cw.Write([]string{"deleted", d.Path, size(d.Size), size(d.Allocated), d.Time.Format(time.RFC3339)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// saveReport writes r to path, as CSV when it ends in .csv and JSON otherwise
+func saveReport(path string, r report) error {
+	write := r.writeJSON
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		write = r.writeCSV
+	}
+	return writeFileAtomic(path, write)
+}
+
+// promptReport asks where to save the report, then saves it and quits. If
+// saving fails the app stays open so nothing is lost.
+func (m model) promptReport() model {
+	m.prompt = &prompt{
+		label: "Save report and quit (.json or .csv):",
+		input: "diskusage-report-" + time.Now().Format("20060102-150405") + ".json",
+		onSubmit: func(m model, value string) (model, tea.Cmd) {
+			if value == "" {
+				return m, nil
+			}
+			if err := saveReport(value, m.newReport()); err != nil {
+				m.status = fmt.Sprintf("Can't save report: %v", err)
+				return m, nil
+			}
+			return m, tea.Quit
+		},
+	}
+	return m
+}
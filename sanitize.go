@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// unprintable reports whether r must not reach the terminal as is: control
+// characters such as newlines, tabs and the escape that starts ANSI
+// sequences, line and paragraph separators, and bytes that aren't valid
+// UTF-8, which some terminals read as 8-bit control codes
+func unprintable(r rune) bool {
+	return r == utf8.RuneError || unicode.IsControl(r) || unicode.In(r, unicode.Zl, unicode.Zp)
+}
+
+// printable replaces characters that could break the layout or drive the
+// terminal with "?", as ls does. It is applied to names and messages only
+// when they are displayed; item paths stay as they are on disk.
+func printable(s string) string {
+	if strings.IndexFunc(s, unprintable) < 0 {
+		return s
+	}
+	var b strings.Builder
+	for i, r := range s {
+		if r == utf8.RuneError {
+			// A valid U+FFFD is kept, an invalid byte replaced
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 3 {
+				b.WriteRune(r)
+				continue
+			}
+		}
+		if unprintable(r) {
+			b.WriteByte('?')
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestPrintable(t *testing.T) {
+	tests := map[string]string{
+		"plain.txt":          "plain.txt",
+		"two\nlines":         "two?lines",
+		"tab\there":          "tab?here",
+		"\x1b[31mred\x1b[0m": "?[31mred?[0m",
+		"bell\a and del\x7f": "bell? and del?",
+		"sep\u2028par\u2029": "sep?par?",
+		"bad \xff byte":      "bad ? byte",
+		"kept � and ünï©":    "kept � and ünï©",
+		"":                   "",
+	}
+	for name, want := range tests {
+		if got := printable(name); got != want {
+			t.Errorf("printable(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
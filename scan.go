@@ -0,0 +1,446 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scanOptions controls how the filesystem is walked, independent of the TUI
+type scanOptions struct {
+	workers     int  // folders sized in parallel; 1 scans serially
+	dirOverhead bool // count directories' own size toward totals, like du
+	noCache     bool // size every folder instead of reusing unchanged ones from the cache
+	virtual     bool // descend into pseudo filesystems such as /proc and /sys
+	created     bool // look up each entry's birth time, with statx in place of lstat on Linux
+
+	symlinks   string // how symbolic links are handled: link, skip or target
+	compressed bool   // take allocated sizes after filesystem compression, where the filesystem reports them
+
+	ignore []string // patterns of the ignore list; matching files and folders are left out
+
+	since, until time.Time // only count entries modified in [since, until); zero is unbounded
+
+	progress func(items int, bytes int64) // if set, called during the walk with the entries and file bytes found so far
+}
+
+// diskSize returns the space a file takes on disk and, with -compressed,
+// whether the filesystem stores it compressed
+func (o scanOptions) diskSize(path string, info os.FileInfo) (int64, bool) {
+	if !o.compressed || info.IsDir() {
+		return allocatedSize(info), false
+	}
+	return compressedSize(path, info)
+}
+
+// lstat returns the FileInfo of a walked entry. With -created or
+// -compressed it comes from the same call that reads the creation time and
+// compression flag.
+func (o scanOptions) lstat(path string, d fs.DirEntry) (os.FileInfo, error) {
+	if !o.created && !o.compressed {
+		return d.Info()
+	}
+	return lstatx(path, d)
+}
+
+// birthTime returns the entry's creation time when -created asked for it
+func (o scanOptions) birthTime(path string, info os.FileInfo) time.Time {
+	if !o.created {
+		return time.Time{}
+	}
+	t, _ := birthTime(path, info)
+	return t
+}
+
+func defaultScanOptions() scanOptions {
+	return scanOptions{workers: runtime.GOMAXPROCS(0), symlinks: symlinksLink}
+}
+
+// dirStats is the recursive summary of a directory
+type dirStats struct {
+	size      int64 // apparent size; hard-linked files count once
+	allocated int64 // bytes allocated on disk
+	count     int   // files and subfolders contained, at any depth
+	inodes    int   // inodes used, the directory's own included; hard links count once
+	partial   bool  // some entries couldn't be reached, so the sizes are too small
+	largest   Item  // biggest file at any depth, by apparent size; empty Path if none
+}
+
+// getDirSize returns the sizes and entry count of everything under path
+// modified within the scan's time range. Directories themselves, path
+// included, only add to the sizes with dirOverhead. Folders in skip aren't
+// descended into, and those in known add their already known totals instead
+// of being walked again; a hard link inside one and outside it then counts
+// twice, in inodes and sizes. Entries refused for lack of permission, such as those of a
+// folder that can be listed but not entered, are passed over and mark the
+// result partial.
+func getDirSize(path string, opts scanOptions, skip map[string]bool, known map[string]Item) (dirStats, error) {
+	var st dirStats
+	linked := map[inodeID]bool{}
+	err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if errors.Is(err, fs.ErrPermission) {
+			st.partial = true
+			if info != nil && info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if skip[p] {
+			return filepath.SkipDir
+		}
+		if p != path && ignoredBy(opts.ignore, p) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, keep := opts.linkInfo(p, info)
+		if !keep {
+			return nil
+		}
+		if k, ok := known[p]; ok && p != path {
+			st.size += k.Size
+			st.allocated += k.Allocated
+			st.count += k.ItemCount + 1
+			st.inodes += k.Inodes
+			if f := k.LargestChild; f != nil && (st.largest.Path == "" || f.Size > st.largest.Size) {
+				st.largest = *f
+			}
+			return filepath.SkipDir
+		}
+		if !opts.inRange(info.ModTime()) {
+			return nil
+		}
+		// Another name of a file already counted takes up no more space
+		seen := false
+		if id, ok := sharedInode(info); ok {
+			seen = linked[id]
+			linked[id] = true
+		}
+		if !seen {
+			st.inodes++
+		}
+		allocated, _ := opts.diskSize(p, info)
+		if !seen && (!info.IsDir() || opts.dirOverhead) {
+			st.size += info.Size()
+			st.allocated += allocated
+		}
+		if !info.IsDir() && (st.largest.Path == "" || info.Size() > st.largest.Size) {
+			st.largest = Item{Path: p, Size: info.Size(), Allocated: allocated, ModTime: info.ModTime()}
+		}
+		if p != path {
+			st.count++
+		}
+		return nil
+	})
+	return st, err
+}
+
+// isHidden reports whether an entry is left out of the listing: its name
+// starts with a dot, or on Windows it carries the hidden or system
+// attribute. The scan root is never hidden by its attributes, as drive roots
+// such as C:\ carry both.
+func isHidden(path, root string, info os.FileInfo) bool {
+	if strings.HasPrefix(filepath.Base(path), ".") {
+		return true
+	}
+	return path != root && osHidden(info)
+}
+
+// scanResult is the outcome of a scan, either from walking the filesystem or
+// from an imported listing
+type scanResult struct {
+	root        string // absolute path of the scan root
+	rootSize    int64  // total size of root itself
+	rootAlloc   int64  // disk space allocated to root
+	rootCount   int    // entries contained in root
+	rootInodes  int    // inodes used by root and everything below it
+	rootPartial bool   // parts of root couldn't be read, so its size is too small
+	rootPending bool   // root hasn't been sized yet, so its size only counts the files found
+	total       int64  // sum of all listed files, each counted exactly once, however many hard links name it
+	totalAlloc  int64  // allocated counterpart of total
+	files       Items
+	folders     Items
+	errors      []scanError   // entries skipped because they couldn't be read
+	skipped     []string      // mount points of pseudo filesystems left out
+	elapsed     time.Duration // how long the scan took, from the start of the walk until every folder was sized
+}
+
+// scanDirectory walks root and returns its files and subfolders, sorted by
+// size, along with the total size of root itself. Files are listed during
+// the walk; folder sizes are then computed by a pool of opts.workers.
+//
+// It doesn't touch the model, so it can be timed on its own; -ndjson runs
+// exactly this and the export.
+func scanDirectory(root string, opts scanOptions) (scanResult, error) {
+	return listTree(root, opts).finish(nil)
+}
+
+// pendingScan is a scan whose tree has been listed but whose folders, apart
+// from those filled from the cache, haven't been sized yet
+type pendingScan struct {
+	res      scanResult // files, totals and listing errors; folders are added by finish
+	dirs     Items      // root first, then every subfolder
+	cached   []bool     // which dirs were filled from the cache; nil without it
+	cache    sizeCache
+	prints   map[string]uint64 // fileFingerprints of the listed files, with the cache
+	useCache bool
+	opts     scanOptions
+	started  time.Time
+	err      error
+}
+
+// listTree walks root, listing its files and folders and filling in the
+// folder sizes the cache still knows
+func listTree(root string, opts scanOptions) *pendingScan {
+	p := &pendingScan{res: scanResult{root: root}, opts: opts, started: time.Now()}
+	res := &p.res
+	devices := map[string]uint64{}
+	linked := map[inodeID]bool{}
+	found := 0
+
+	p.err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			res.errors = append(res.errors, scanError{path: path, err: err})
+			return nil
+		}
+		info, err := opts.lstat(path, d)
+		if err != nil {
+			res.errors = append(res.errors, scanError{path: path, err: err})
+			return nil
+		}
+		if isHidden(path, root, info) || path != root && ignoredBy(opts.ignore, path) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		link := info.Mode()&os.ModeSymlink != 0
+		info, keep := opts.linkInfo(path, info)
+		if !keep {
+			return nil
+		}
+
+		if info.IsDir() {
+			dir := Item{Path: path, ModTime: info.ModTime(), Created: opts.birthTime(path, info), IsDir: true}
+			if dev, ok := deviceOf(info); ok {
+				devices[path] = dev
+				parent, seen := devices[filepath.Dir(path)]
+				dir.IsMount = path != root && seen && parent != dev
+			}
+			if dir.IsMount && !opts.virtual && virtualFS(path) {
+				res.skipped = append(res.skipped, path)
+				return filepath.SkipDir
+			}
+			p.dirs = append(p.dirs, dir)
+			if opts.dirOverhead && opts.inRange(info.ModTime()) {
+				res.total += info.Size()
+				res.totalAlloc += allocatedSize(info)
+			}
+		} else if opts.inRange(info.ModTime()) {
+			allocated, compressed := opts.diskSize(path, info)
+			res.files = append(res.files, Item{Path: path, Size: info.Size(), Allocated: allocated, ModTime: info.ModTime(), Created: opts.birthTime(path, info),
+				IsLink: link, LinkTarget: link && info.Mode()&os.ModeSymlink == 0, Compressed: compressed})
+			// Every name of a hard-linked file is listed, but its data counts once
+			id, shared := sharedInode(info)
+			if !shared || !linked[id] {
+				res.total += info.Size()
+				res.totalAlloc += allocated
+			}
+			if shared {
+				linked[id] = true
+			}
+		}
+		if found++; opts.progress != nil {
+			opts.progress(found, res.total)
+		}
+		return nil
+	})
+	sort.Sort(res.files)
+
+	// Sizes limited to a time range don't describe the folders in general
+	p.useCache = !opts.noCache && !opts.timeFiltered()
+	if p.useCache {
+		p.cache = loadSizeCache(opts)
+		p.prints = fileFingerprints(res.files)
+		p.cached = p.cache.fill(root, p.dirs, p.prints)
+	}
+	return p
+}
+
+// preview returns the listing before the folders are sized: every folder
+// the cache didn't fill is marked SizePending with no size yet, and the
+// root counts only the files found
+func (p *pendingScan) preview() scanResult {
+	res := p.res
+	res.files = slices.Clone(p.res.files)
+	res.rootSize, res.rootAlloc, res.rootPending = res.total, res.totalAlloc, true
+	for i, dir := range p.dirs {
+		pending := p.cached == nil || !p.cached[i]
+		if dir.Path == res.root {
+			if !pending {
+				res.rootSize, res.rootAlloc, res.rootCount, res.rootInodes = dir.Size, dir.Allocated, dir.ItemCount, dir.Inodes
+				res.rootPending = false
+			}
+			continue
+		}
+		dir.SizePending = pending
+		res.folders = append(res.folders, dir)
+	}
+	sort.Sort(res.folders)
+	return res
+}
+
+// finish sizes the folders the cache couldn't fill, updates the cache and
+// returns the complete result. sized, if set, is called with each folder as
+// soon as it has been sized, from the sizing goroutines but never two at
+// once.
+func (p *pendingScan) finish(sized func(dir Item)) (scanResult, error) {
+	res, dirs := p.res, p.dirs
+	skip := make(map[string]bool, len(res.skipped))
+	for _, path := range res.skipped {
+		skip[path] = true
+	}
+	var checkpoint func(done []bool)
+	if p.useCache {
+		checkpoint = func(done []bool) {
+			_ = p.cache.checkpoint(dirs, p.prints, p.cached, done)
+		}
+	}
+	var onSized func(i int)
+	if sized != nil {
+		onSized = func(i int) { sized(dirs[i]) }
+	}
+	errs := sizeFolders(dirs, p.cached, skip, p.opts, checkpoint, onSized)
+	if p.useCache {
+		// The cache only saves time; failing to write it doesn't affect the scan
+		_ = p.cache.update(res.root, dirs, p.prints, errs)
+	}
+	for i, dir := range dirs {
+		if errs[i] != nil {
+			res.errors = append(res.errors, scanError{path: dir.Path, err: fmt.Errorf("folder left out: %w", errs[i])})
+			continue
+		}
+		if dir.Path == res.root {
+			res.rootSize, res.rootAlloc, res.rootCount, res.rootInodes = dir.Size, dir.Allocated, dir.ItemCount, dir.Inodes
+			res.rootPartial = dir.SizePartial
+			continue
+		}
+		res.folders = append(res.folders, dir)
+	}
+	sort.Sort(res.folders)
+
+	res.elapsed = time.Since(p.started)
+	return res, p.err
+}
+
+// checkpointInterval is how often a long scan saves the folders sized so
+// far, bounding the work an interruption loses
+const checkpointInterval = 30 * time.Second
+
+// sizeFolders fills in the recursive size of each folder using up to
+// opts.workers goroutines, skipping those already filled from the cache and
+// the pseudo filesystems in skip. Cached folders also stand in for their
+// subtree when an enclosing folder is sized. Every checkpointInterval,
+// checkpoint, if set, is called with the folders sized so far; no folder is
+// written meanwhile. sized, if set, is called with the index of each folder
+// just sized, under the same lock. It returns the error for each folder that
+// couldn't be sized; those are left out of the results.
+func sizeFolders(dirs Items, cached []bool, skip map[string]bool, opts scanOptions, checkpoint func(done []bool), sized func(i int)) []error {
+	errs := make([]error, len(dirs))
+	workers := max(1, min(opts.workers, len(dirs)))
+	known := map[string]Item{}
+	for i, dir := range dirs {
+		if cached != nil && cached[i] {
+			known[dir.Path] = dir
+		}
+	}
+
+	var mu sync.Mutex
+	done := make([]bool, len(dirs))
+	lastCheckpoint := time.Now()
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Each job owns its index, so writes never overlap
+			for i := range jobs {
+				st, err := getDirSize(dirs[i].Path, opts, skip, known)
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				mu.Lock()
+				dirs[i].Size, dirs[i].Allocated, dirs[i].ItemCount, dirs[i].Inodes = st.size, st.allocated, st.count, st.inodes
+				dirs[i].SizePartial = st.partial
+				if st.largest.Path != "" {
+					largest := st.largest
+					dirs[i].LargestChild = &largest
+				}
+				done[i] = true
+				if sized != nil {
+					sized(i)
+				}
+				if checkpoint != nil && time.Since(lastCheckpoint) >= checkpointInterval {
+					checkpoint(done)
+					lastCheckpoint = time.Now()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range dirs {
+		if cached == nil || !cached[i] {
+			jobs <- i
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return errs
+}
+
+// loadScan produces the scan results for path, or reads them from stdin
+// when importing a listing
+func loadScan(path string, opts options) (scanResult, error) {
+	if opts.fromNDJSON {
+		return readNDJSON(os.Stdin)
+	}
+
+	root, err := scanRoot(path)
+	if err != nil {
+		return scanResult{}, err
+	}
+	return scanDirectory(root, opts.scan)
+}
+
+// scanRoot turns the path given on the command line into the absolute,
+// real path that is walked
+func scanRoot(path string) (string, error) {
+	path, err := resolveDevice(path)
+	if err != nil {
+		return "", err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	// Resolve a symlinked root so Walk descends into it and basePath, the
+	// displayed relative paths and deletions all refer to the real location
+	return filepath.EvalSymlinks(absPath)
+}
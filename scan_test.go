@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// writeFile creates path, and any missing parents, holding size zero bytes
+func writeFile(tb testing.TB, path string, size int) {
+	tb.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		tb.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// isolateUserDirs points the user's home, config and cache folders at a
+// temporary directory, keeping the size cache a test's scans write out of
+// the real one
+func isolateUserDirs(tb testing.TB) {
+	dir := tb.TempDir()
+	tb.Setenv("HOME", dir)
+	tb.Setenv("XDG_CONFIG_HOME", dir)
+	tb.Setenv("XDG_CACHE_HOME", dir)
+}
+
+func TestScanTotal(t *testing.T) {
+	isolateUserDirs(t)
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a", "b", "big"), 100)
+	writeFile(t, filepath.Join(root, "a", "mid"), 50)
+	writeFile(t, filepath.Join(root, "small"), 10)
+
+	res, err := scanDirectory(root, defaultScanOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every listed file counts once, however deep the folders nest
+	var files int64
+	for _, f := range res.files {
+		files += f.Size
+	}
+	if res.total != files || res.total != 160 {
+		t.Errorf("total %d, want the %d bytes of the listed files (160)", res.total, files)
+	}
+	var folders int64
+	for _, f := range res.folders {
+		folders += f.Size
+	}
+	if folders == res.total {
+		t.Errorf("folder sizes add up to the total %d; nested folders should overlap", folders)
+	}
+	if res.rootSize != res.total {
+		t.Errorf("root size %d, want %d", res.rootSize, res.total)
+	}
+}
+
+func TestScanSymlinkedRoot(t *testing.T) {
+	isolateUserDirs(t)
+	dir := t.TempDir()
+	target := filepath.Join(dir, "real")
+	writeFile(t, filepath.Join(target, "sub", "file"), 42)
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("no symlinks here: %v", err)
+	}
+	want, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The walk descends into the target instead of listing the link itself
+	res, err := loadScan(link, options{scan: defaultScanOptions()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.root != want {
+		t.Fatalf("scanned %q, want %q", res.root, want)
+	}
+	if res.rootSize != 42 || len(res.files) != 1 || len(res.folders) != 1 {
+		t.Fatalf("scanned %d bytes in %d files and %d folders, want 42 in 1 and 1", res.rootSize, len(res.files), len(res.folders))
+	}
+	if f := res.files[0]; f.Path != filepath.Join(want, "sub", "file") {
+		t.Errorf("listed %q, want the file below the real path", f.Path)
+	}
+}
+
+func TestScanCacheSeesRewrittenFiles(t *testing.T) {
+	isolateUserDirs(t)
+	root := t.TempDir()
+	dir := filepath.Join(root, "a", "b")
+	writeFile(t, filepath.Join(dir, "file"), 100)
+	if _, err := scanDirectory(root, defaultScanOptions()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rewriting a file in place leaves its folder's mtime as it was
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "file"), 300)
+	if err := os.Chtimes(dir, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := scanDirectory(root, defaultScanOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.rootSize != 300 {
+		t.Errorf("root size %d after the rewrite, want 300", res.rootSize)
+	}
+	for _, f := range res.folders {
+		if f.Size != 300 {
+			t.Errorf("%s is %d bytes, want 300", f.Path, f.Size)
+		}
+	}
+}
+
+func TestScanTotalWithHardLinks(t *testing.T) {
+	isolateUserDirs(t)
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a", "b", "big"), 100)
+	writeFile(t, filepath.Join(root, "small"), 10)
+	if err := os.Link(filepath.Join(root, "a", "b", "big"), filepath.Join(root, "a", "link")); err != nil {
+		t.Skipf("no hard links here: %v", err)
+	}
+
+	res, err := scanDirectory(root, defaultScanOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both names of the linked file are listed, but its bytes and its inode
+	// count once: root, a, a/b, big and small
+	if len(res.files) != 3 {
+		t.Errorf("listed %d files, want 3", len(res.files))
+	}
+	if res.total != 110 || res.rootSize != 110 {
+		t.Errorf("total %d and root size %d, want 110", res.total, res.rootSize)
+	}
+	if res.rootInodes != 5 {
+		t.Errorf("root uses %d inodes, want 5", res.rootInodes)
+	}
+	for _, f := range res.folders {
+		if f.Path == filepath.Join(root, "a") && (f.Size != 100 || f.Inodes != 3) {
+			t.Errorf("a holds %d bytes in %d inodes, want 100 in 3", f.Size, f.Inodes)
+		}
+	}
+}
+
+// benchTree builds the same tree for every benchmark: 16 folders of 8
+// subfolders, each holding 16 files of up to 4 KiB
+func benchTree(b *testing.B) string {
+	b.Helper()
+	root := b.TempDir()
+	for i := range 16 {
+		for j := range 8 {
+			for k := range 16 {
+				writeFile(b, filepath.Join(root, fmt.Sprintf("dir%02d", i), fmt.Sprintf("sub%d", j), fmt.Sprintf("file%02d", k)), (i*j*k)%4096)
+			}
+		}
+	}
+	return root
+}
+
+func benchmarkScan(b *testing.B, workers int) {
+	root := benchTree(b)
+	opts := defaultScanOptions()
+	opts.workers = workers
+	opts.noCache = true // time the walk, not the cache
+	b.ResetTimer()
+	for range b.N {
+		if _, err := scanDirectory(root, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkScanSerial(b *testing.B) {
+	benchmarkScan(b, 1)
+}
+
+func BenchmarkScanConcurrent(b *testing.B) {
+	benchmarkScan(b, runtime.GOMAXPROCS(0))
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// scanProgressMsg reports what a background scan has found so far. ch
+// delivers the next progress or the message the scan finishes with.
+type scanProgressMsg struct {
+	items int   // files and folders found
+	bytes int64 // apparent size of the files found
+	ch    <-chan tea.Msg
+}
+
+// scanStats sums up a finished scan for -timing, such as "1.2 TB in 8.4s
+// (143 GB/s effective)". Folders filled from the cache aren't read again,
+// so the rate is what the scan achieved, not the disk's speed.
+func (m model) scanStats(res scanResult) string {
+	total := res.total
+	if m.opts.allocated {
+		total = res.totalAlloc
+	}
+	s := fmt.Sprintf("%s in %s", m.formatSize(total), formatElapsed(res.elapsed))
+	if secs := res.elapsed.Seconds(); secs > 0 {
+		s += fmt.Sprintf(" (%s/s effective)", m.formatSize(int64(float64(total)/secs)))
+	}
+	return s
+}
+
+// formatElapsed rounds a scan duration for display: milliseconds under a
+// second, tenths of a second above
+func formatElapsed(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// backgroundScan scans path without blocking the TUI, sending progress back
+// through Update while the tree is walked. done turns the outcome into the
+// final message.
+func backgroundScan(path string, opts options, done func(scanResult, error) tea.Msg) tea.Cmd {
+	ch := make(chan tea.Msg, 1)
+	go func() {
+		var last time.Time
+		opts.scan.progress = func(items int, bytes int64) {
+			if time.Since(last) < copyProgressInterval {
+				return
+			}
+			last = time.Now()
+			select {
+			case ch <- scanProgressMsg{items: items, bytes: bytes, ch: ch}:
+			default:
+			}
+		}
+		res, err := loadScan(path, opts)
+		ch <- done(res, err)
+	}()
+	return waitForCopy(ch)
+}
+
+// scanTitle counts up in the title while a background scan runs, so a slow
+// volume visibly makes progress
+func (m model) scanTitle() string {
+	return fmt.Sprintf("- scanning: %s items, %s found ", m.loc.comma(int64(m.scanning.items)), m.formatSize(m.scanning.bytes))
+}
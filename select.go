@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// isSelected reports whether item is selected. Selection is kept by path
+// rather than on the lists, so re-sorting, filtering, switching views and
+// rescanning never lose or move it. The loose row is selected when all the
+// files it sums up are.
+func (m model) isSelected(item Item) bool {
+	if item.IsLoose {
+		return m.looseSelected()
+	}
+	return m.selected[item.Path]
+}
+
+// setSelected selects or deselects the item at path in every view
+func (m model) setSelected(path string, on bool) model {
+	if !on {
+		delete(m.selected, path)
+		return m
+	}
+	if m.selected == nil {
+		m.selected = map[string]bool{}
+	}
+	m.selected[path] = true
+	return m
+}
+
+// keepFirst selects every deletable item after the first n visible rows, so
+// only the top n by the current sort survive a delete
+func (m model) keepFirst(n int) model {
+	rows := m.rows()
+	kept, selected := 0, 0
+	for row := 0; row < rows.len(); row++ {
+		item := rows.at(row)
+		if !item.deletable() {
+			continue
+		}
+		if kept < n {
+			m = m.setSelected(item.Path, false)
+			kept++
+			continue
+		}
+		m = m.setSelected(item.Path, true)
+		selected++
+	}
+	m.status = fmt.Sprintf("Selected %d items, keeping the first %d - press d to delete", selected, kept)
+	return m
+}
+
+// promptKeep asks how many items to keep before selecting the rest
+func (m model) promptKeep() model {
+	m.prompt = &prompt{
+		label: "Keep how many items (rest get selected)? ",
+		onSubmit: func(m model, value string) (model, tea.Cmd) {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 0 {
+				m.status = fmt.Sprintf("Not a valid count: %q", value)
+				return m, nil
+			}
+			return m.keepFirst(n), nil
+		},
+	}
+	return m
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// press sends m a key press
+func press(m model, msg tea.KeyMsg) model {
+	next, _ := m.Update(msg)
+	return next.(model)
+}
+
+func TestSelectionSurvivesSortAndFilter(t *testing.T) {
+	m := resize(testModel(t, 20), 100, 40)
+
+	// Select the rows at 0, 3 and 7 by moving down and pressing space
+	var want []string
+	for row := 0; row < 8; row++ {
+		if row == 0 || row == 3 || row == 7 {
+			want = append(want, m.rows().at(m.cursor).Path)
+			m = press(m, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+		}
+		m = press(m, tea.KeyMsg{Type: tea.KeyDown})
+	}
+	slices.Sort(want)
+	check := func(step string) {
+		t.Helper()
+		if got := slices.Sorted(maps.Keys(m.selected)); !slices.Equal(got, want) {
+			t.Errorf("after %s selected %q, want %q", step, got, want)
+		}
+		var shown []string
+		rows := m.rows()
+		for row := 0; row < rows.len(); row++ {
+			if item := rows.at(row); m.isSelected(*item) {
+				shown = append(shown, item.Path)
+			}
+		}
+		slices.Sort(shown)
+		if m.filter == "" && !slices.Equal(shown, want) {
+			t.Errorf("after %s rows marked %q, want %q", step, shown, want)
+		}
+	}
+	check("selecting")
+
+	m = m.setSort(sortByName, false)
+	check("sorting by name")
+	m = m.setFilter("file1")
+	check("filtering")
+	m = m.setFilter("")
+	check("clearing the filter")
+}
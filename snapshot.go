@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// deltaWidth is the width of the DELTA column: a signed size plus " gone"
+func (m model) deltaWidth() int {
+	return m.sizeWidth() + 6
+}
+
+// changeKind describes how an item differs from a loaded snapshot
+type changeKind int
+
+const (
+	changeNone    changeKind = iota
+	changeAdded              // present now, missing from the snapshot
+	changeRemoved            // present in the snapshot, gone now
+)
+
+// snapshot is the on-disk record of a scan, used to compare sizes over time.
+// Paths are stored relative to Root so a moved tree still compares cleanly.
+type snapshot struct {
+	Root     string          `json:"root"`
+	Created  time.Time       `json:"created"`
+	RootSize int64           `json:"root_size"`
+	Files    []snapshotEntry `json:"files"`
+	Folders  []snapshotEntry `json:"folders"`
+}
+
+type snapshotEntry struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+func newSnapshot(root string, rootSize int64, files, folders Items) snapshot {
+	entries := func(items Items) []snapshotEntry {
+		out := make([]snapshotEntry, 0, len(items))
+		for _, item := range items {
+			out = append(out, snapshotEntry{Path: getRelativePath(item.Path, root), Size: item.Size})
+		}
+		return out
+	}
+	return snapshot{
+		Root:     root,
+		Created:  time.Now(),
+		RootSize: rootSize,
+		Files:    entries(files),
+		Folders:  entries(folders),
+	}
+}
+
+func saveSnapshot(path string, snap snapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+func loadSnapshot(path string) (snapshot, error) {
+	var snap snapshot
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snap, err
+	}
+	err = json.Unmarshal(data, &snap)
+	return snap, err
+}
+
+// applyDelta records each item's growth since the snapshot and appends
+// placeholder items for anything that has since disappeared
+func applyDelta(items Items, then []snapshotEntry, root string, dirs bool) Items {
+	old := make(map[string]int64, len(then))
+	for _, e := range then {
+		old[e.Path] = e.Size
+	}
+
+	for i := range items {
+		rel := getRelativePath(items[i].Path, root)
+		size, ok := old[rel]
+		if !ok {
+			items[i].Change = changeAdded
+			items[i].Delta = items[i].Size
+			continue
+		}
+		items[i].Delta = items[i].Size - size
+		delete(old, rel)
+	}
+
+	// Iterate the snapshot order so removed entries keep a stable order
+	for _, e := range then {
+		if _, gone := old[e.Path]; gone {
+			items = append(items, Item{
+				Path:   filepath.Join(root, e.Path),
+				Delta:  -e.Size,
+				IsDir:  dirs,
+				Change: changeRemoved,
+			})
+		}
+	}
+	return items
+}
+
+// deltaColumn renders the padded, colored DELTA cell for an item
+func (m model) deltaColumn(item Item) string {
+	var text string
+	switch {
+	case item.Delta > 0:
+		text = "+" + m.formatSize(item.Delta)
+	case item.Delta < 0:
+		text = "-" + m.formatSize(-item.Delta)
+	default:
+		text = "="
+	}
+	switch item.Change {
+	case changeAdded:
+		text += " new"
+	case changeRemoved:
+		text += " gone"
+	}
+
+	// Pad before styling so escape codes don't skew the column width
+	text = padLeft(text, m.deltaWidth())
+	switch {
+	case item.Delta > 0:
+		return m.styles.deltaUp.Render(text)
+	case item.Delta < 0:
+		return m.styles.deltaDown.Render(text)
+	}
+	return text
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sortKey selects the column the item lists are ordered by
+type sortKey int
+
+const (
+	sortNone   sortKey = -1 // columns that can't be sorted by
+	sortBySize sortKey = iota - 1
+	sortByDelta
+	sortByCount
+	sortByAvg
+	sortByDepth
+	sortByInodes
+	sortByName
+	sortByExt
+	sortByPath
+	sortByMtime // only through -sort; there is no column to click
+	sortByCreated
+)
+
+var sortKeyNames = map[sortKey]string{
+	sortBySize:    "size",
+	sortByDelta:   "delta",
+	sortByCount:   "count",
+	sortByAvg:     "avg",
+	sortByDepth:   "depth",
+	sortByInodes:  "inodes",
+	sortByName:    "name",
+	sortByExt:     "ext",
+	sortByPath:    "path",
+	sortByMtime:   "mtime",
+	sortByCreated: "created",
+}
+
+// sortClause is one key of a layered sort such as "ext,size:desc,name"
+type sortClause struct {
+	key  sortKey
+	desc bool
+}
+
+// parseSort parses a -sort value: one key such as "name" or "size:asc", or
+// several separated by commas, each breaking the ties left by those before
+func parseSort(spec string) ([]sortClause, error) {
+	var chain []sortClause
+	for _, part := range strings.Split(spec, ",") {
+		c, err := parseSortClause(part)
+		if err != nil {
+			return nil, err
+		}
+		if slices.ContainsFunc(chain, func(prev sortClause) bool { return prev.key == c.key }) {
+			return nil, fmt.Errorf("sort column %q given twice", sortKeyNames[c.key])
+		}
+		chain = append(chain, c)
+	}
+	return chain, nil
+}
+
+func parseSortClause(spec string) (sortClause, error) {
+	name, dir, hasDir := strings.Cut(spec, ":")
+	for key, n := range sortKeyNames {
+		if n != name {
+			continue
+		}
+		switch {
+		case !hasDir:
+			return sortClause{key, key.defaultDesc()}, nil
+		case dir == "asc":
+			return sortClause{key, false}, nil
+		case dir == "desc":
+			return sortClause{key, true}, nil
+		}
+		return sortClause{}, fmt.Errorf("invalid sort direction %q: use asc or desc", dir)
+	}
+	return sortClause{}, fmt.Errorf("invalid sort column %q: use size, delta, count, avg, depth, inodes, name, ext, path, mtime or created", name)
+}
+
+// String formats the clause as it is written in -sort
+func (c sortClause) String() string {
+	spec := sortKeyNames[c.key]
+	if c.desc != c.key.defaultDesc() {
+		if c.desc {
+			spec += ":desc"
+		} else {
+			spec += ":asc"
+		}
+	}
+	return spec
+}
+
+// sortChain returns the active sort: the primary key, then the tie-breakers
+// given with -sort
+func (m model) sortChain() []sortClause {
+	chain := []sortClause{{m.sortKey, m.sortDesc}}
+	for _, c := range m.thenBy {
+		if c.key != m.sortKey {
+			chain = append(chain, c)
+		}
+	}
+	return chain
+}
+
+// sortSpec formats the active sort as a -sort value
+func (m model) sortSpec() string {
+	var parts []string
+	for _, c := range m.sortChain() {
+		parts = append(parts, c.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// defaultDesc reports the natural direction for a column: biggest first for
+// sizes, newest first for times, alphabetical for names and paths
+func (k sortKey) defaultDesc() bool {
+	return k == sortBySize || k == sortByDelta || k == sortByCount || k == sortByAvg || k == sortByDepth || k == sortByInodes || k == sortByMtime || k == sortByCreated
+}
+
+// compare orders two items by the key in ascending order, returning 0 when
+// they tie. Sizes compare allocated bytes when allocated is set.
+func (k sortKey) compare(a, b Item, allocated bool) int {
+	switch k {
+	case sortBySize:
+		if allocated {
+			return cmp.Compare(a.Allocated, b.Allocated)
+		}
+		return cmp.Compare(a.Size, b.Size)
+	case sortByDelta:
+		return cmp.Compare(a.Delta, b.Delta)
+	case sortByCount:
+		return cmp.Compare(a.ItemCount, b.ItemCount)
+	case sortByAvg:
+		return cmp.Compare(a.avgSize(allocated), b.avgSize(allocated))
+	case sortByInodes:
+		return cmp.Compare(a.Inodes, b.Inodes)
+	case sortByDepth:
+		return cmp.Compare(strings.Count(a.Path, string(filepath.Separator)), strings.Count(b.Path, string(filepath.Separator)))
+	case sortByName:
+		return cmp.Compare(strings.ToLower(filepath.Base(a.Path)), strings.ToLower(filepath.Base(b.Path)))
+	case sortByExt:
+		return cmp.Compare(strings.ToLower(filepath.Ext(a.Path)), strings.ToLower(filepath.Ext(b.Path)))
+	case sortByMtime:
+		return a.ModTime.Compare(b.ModTime)
+	case sortByCreated:
+		return a.Created.Compare(b.Created)
+	case sortByPath:
+		if a.Path == b.Path {
+			return 0
+		}
+		if treeLess(a.Path, b.Path) {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// less reports whether a sorts before b under chain. Ties left by every
+// clause go biggest first, so same-type files sorted by extension stay
+// ordered by size, and then by path so the order is deterministic.
+func less(a, b Item, chain []sortClause, allocated bool) bool {
+	for _, c := range chain {
+		r := c.key.compare(a, b, allocated)
+		if c.desc {
+			r = -r
+		}
+		if r != 0 {
+			return r < 0
+		}
+	}
+	if r := sortBySize.compare(a, b, allocated); r != 0 {
+		return r > 0
+	}
+	return a.Path < b.Path
+}
+
+// treeLess orders paths depth-first, like a directory tree: the separator
+// sorts before any other character so "a/b" stays next to "a" rather than
+// after "a-b"
+func treeLess(a, b string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		ca, cb := a[i], b[i]
+		if ca == cb {
+			continue
+		}
+		if ca == filepath.Separator {
+			return true
+		}
+		if cb == filepath.Separator {
+			return false
+		}
+		return ca < cb
+	}
+	return len(a) < len(b)
+}
+
+// treeIndent returns the indentation showing an item's level below the
+// folder being browsed when listing in tree order
+func (m model) treeIndent(item Item) string {
+	if m.sortKey != sortByPath || m.sortDesc || item.IsRoot {
+		return ""
+	}
+	rel := getRelativePath(item.Path, m.dir())
+	return strings.Repeat("  ", strings.Count(rel, string(filepath.Separator)))
+}
+
+// sortItems orders items by chain, keeping a pinned root row at the top
+func sortItems(items Items, chain []sortClause, allocated bool) {
+	if len(items) > 0 && items[0].IsRoot {
+		items = items[1:]
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return less(items[i], items[j], chain, allocated)
+	})
+}
+
+// sortKeys returns the columns available for sorting in display order
+func (m model) sortKeys() []sortKey {
+	keys := []sortKey{sortBySize}
+	for _, col := range m.extraColumns() {
+		if col.key != sortNone && !slices.Contains(keys, col.key) {
+			keys = append(keys, col.key)
+		}
+	}
+	return append(keys, sortByName, sortByExt, sortByPath)
+}
+
+// setSort changes the primary sort key and reorders every view; the
+// tie-breakers from -sort still apply after it
+func (m model) setSort(key sortKey, desc bool) model {
+	m.sortKey = key
+	m.sortDesc = desc
+	chain := m.sortChain()
+	sortItems(m.files, chain, m.opts.allocated)
+	sortItems(m.folders, chain, m.opts.allocated)
+	sortItems(m.combined, chain, m.opts.allocated)
+	return m
+}
+
+// cycleSort advances to the next sortable column in its natural direction
+func (m model) cycleSort() model {
+	keys := m.sortKeys()
+	next := keys[0]
+	for i, k := range keys {
+		if k == m.sortKey {
+			next = keys[(i+1)%len(keys)]
+			break
+		}
+	}
+	return m.setSort(next, next.defaultDesc())
+}
+
+// sortIndicator returns the arrow shown next to the active sort column
+func (m model) sortIndicator(key sortKey) string {
+	if key != m.sortKey {
+		return ""
+	}
+	if m.sortDesc {
+		return " ▼"
+	}
+	return " ▲"
+}
+
+// handleMouse sorts by a header column when it is clicked and scrolls the
+// list with the mouse wheel
+func (m model) handleMouse(msg tea.MouseMsg) model {
+	if msg.Action != tea.MouseActionPress {
+		return m
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		return m.moveCursor(-1)
+	case tea.MouseButtonWheelDown:
+		return m.moveCursor(1)
+	case tea.MouseButtonLeft:
+		if msg.Y != m.headerRow() {
+			return m
+		}
+		key, ok := m.columns().keyAt(msg.X)
+		if !ok {
+			return m
+		}
+		if key == m.sortKey {
+			return m.setSort(key, !m.sortDesc)
+		}
+		return m.setSort(key, key.defaultDesc())
+	}
+	return m
+}
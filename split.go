@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// paneMsg carries a message back to the pane whose command produced it, so
+// the result of a scan or deletion started in one pane of a split screen
+// never lands in the other
+type paneMsg struct {
+	pane int
+	msg  tea.Msg
+}
+
+// forPane tags the messages cmd produces with the pane they belong to.
+// Batches are tagged command by command, and quitting is left for bubbletea
+// to see.
+func forPane(pane int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case nil, tea.QuitMsg:
+			return msg
+		case tea.BatchMsg:
+			for i := range msg {
+				msg[i] = forPane(pane, msg[i])
+			}
+			return msg
+		default:
+			return paneMsg{pane, msg}
+		}
+	}
+}
+
+// Update hands msg to the pane it belongs to: tagged results to the pane
+// that asked for them, input to the active one. The commands returned are
+// tagged with the pane that comes back, as switching panes changes it.
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case paneMsg:
+		if msg.pane == m.paneID {
+			return m.updatePane(msg.msg)
+		}
+		if m.pane == nil || m.pane.paneID != msg.pane {
+			return m, nil // from a pane closed since
+		}
+		next, cmd := m.pane.update(msg.msg)
+		other := next.(model)
+		m.pane = &other
+		return m, forPane(other.paneID, cmd)
+	case tea.MouseMsg:
+		if m.pane != nil && !m.paneRight {
+			// the active pane is on the right, past the other one and the divider
+			msg.X -= m.pane.width + 1
+		}
+		if msg.X < 0 || msg.X >= m.width {
+			return m, nil
+		}
+		return m.updatePane(msg)
+	}
+	return m.updatePane(msg)
+}
+
+func (m model) updatePane(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	return next, forPane(next.(model).paneID, cmd)
+}
+
+// branch scans the folder under the cursor, or else the one being browsed,
+// in a second pane beside this one, which keeps its scan and place as they
+// are. With the screen already split it closes the other pane instead.
+func (m model) branch() (model, tea.Cmd) {
+	if m.pane != nil {
+		return m.closePane(), nil
+	}
+	if m.opts.fromNDJSON {
+		m.status = "A second pane needs a local scan"
+		return m, nil
+	}
+	path := m.dir()
+	if item, ok := m.currentItem(); ok && item.IsDir && !item.IsLoose && item.Change != changeRemoved {
+		path = item.Path
+	}
+
+	m.lastPane++
+	b := m
+	b.paneID, b.paneRight = m.lastPane, false
+	b.pane = &m
+	// The new pane starts with nothing of its own to act on or compare, and
+	// the work still running here reports back to this pane only
+	b.selected, b.deleted, b.snapBefore, b.snapAfter = nil, nil, nil, nil
+	b.deleting, b.progress, b.hashing, b.refreshing, b.sudo = nil, nil, nil, false, nil
+	return b.resized().scanPath(path)
+}
+
+// closePane leaves the active pane alone on the screen. A pane still busy
+// is kept, as what it's waiting for would be lost.
+func (m model) closePane() model {
+	if busy := m.pane.busy(); busy != "" {
+		m.status = "The other pane is still busy with " + busy
+		return m
+	}
+	m.pane = nil
+	m.status = "Closed the other pane"
+	return m.resized()
+}
+
+// switchPane makes the other pane the active one
+func (m model) switchPane() model {
+	if m.pane == nil {
+		m.status = fmt.Sprintf("No other pane - press %s to scan the folder under the cursor beside this one", m.keys.short(actSplit))
+		return m
+	}
+	other := *m.pane
+	m.pane = nil
+	other.pane, other.paneRight, other.lastPane = &m, !m.paneRight, m.lastPane
+	return other
+}
+
+// busy names the background work a pane is waiting for, or ""
+func (m model) busy() string {
+	switch {
+	case m.scanning != nil || m.sizing != nil:
+		return "a scan"
+	case m.deleting != nil:
+		return "a deletion"
+	case m.progress != nil:
+		return "a copy"
+	case m.hashing != nil:
+		return "checksums"
+	}
+	return ""
+}
+
+// resized fits the panes to the terminal: the whole of it for a single
+// pane, or either side of a one-column divider when split
+func (m model) resized() model {
+	full := m.windowSize
+	m.height = full.Height
+	if m.pane == nil {
+		m.width = full.Width
+		return m.clampView()
+	}
+	left := (full.Width - 1) / 2
+	right := full.Width - 1 - left
+	other := *m.pane
+	other.windowSize, other.height = full, full.Height
+	if m.paneRight {
+		m.width, other.width = left, right
+	} else {
+		m.width, other.width = right, left
+	}
+	other = other.clampView()
+	m.pane = &other
+	return m.clampView()
+}
+
+// View renders the active pane, and the other one beside it when the screen
+// is split. The other pane's status line says how to switch to it.
+func (m model) View() string {
+	if m.pane == nil {
+		return m.screen()
+	}
+	other := *m.pane
+	hint := fmt.Sprintf("Press %s to switch to this pane", m.keys.short(actSwitchPane))
+	if other.status != "" {
+		hint = other.status + " • " + hint
+	}
+	other.status = hint
+
+	fit := func(s string, width int) string {
+		return lipgloss.NewStyle().MaxWidth(width).Render(lipgloss.PlaceHorizontal(width, lipgloss.Left, s))
+	}
+	left, right := fit(m.screen(), m.width), fit(other.screen(), other.width)
+	if !m.paneRight {
+		left, right = right, left
+	}
+	lines := max(lipgloss.Height(left), lipgloss.Height(right))
+	divider := m.styles.helpText.Render(strings.TrimSuffix(strings.Repeat("│\n", lines), "\n"))
+	return lipgloss.JoinHorizontal(lipgloss.Top, left, divider, right)
+}
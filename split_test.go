@@ -0,0 +1,34 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitDuringDelete(t *testing.T) {
+	m := treeModel(t)
+	m = m.setSelected(filepath.Join(m.basePath, "top"), true)
+	m, _ = m.deleteSelected()
+
+	b, cmd := m.branch()
+	if b.deleting != nil || b.progress != nil || b.hashing != nil || b.refreshing {
+		t.Fatal("the new pane took over the other pane's running work")
+	}
+	if busy := b.busy(); busy == "a deletion" {
+		t.Error("the new pane is busy with the other pane's deletion")
+	}
+	if b.pane == nil || b.pane.deleting == nil {
+		t.Error("the other pane lost its deletion")
+	}
+	waitForDelete(t, *b.pane)
+
+	// The new pane's own scan writes to the size cache, so it has to finish
+	// before the test's folders are removed
+	for msg := cmd(); ; {
+		progress, ok := msg.(scanProgressMsg)
+		if !ok {
+			break
+		}
+		msg = <-progress.ch
+	}
+}
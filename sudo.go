@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sudoRetry is a deletion that failed for lack of permission and can be
+// retried through sudo, only ever after the user confirms it
+type sudoRetry struct {
+	item    Item
+	asking  bool // the y/n question is showing
+	command []string
+}
+
+// sudoDoneMsg reports the outcome of a deletion run through sudo
+type sudoDoneMsg struct {
+	item Item
+	err  error
+}
+
+// permissionFailure turns a deletion refused with EACCES or EPERM into a
+// readable message, offering a sudo retry where one is possible. It
+// returns false for other errors.
+func (m model) permissionFailure(item Item, err error) (model, bool) {
+	if !errors.Is(err, fs.ErrPermission) {
+		return m, false
+	}
+	m.status = fmt.Sprintf("Permission denied - you may need to run as root to delete %s", filepath.Base(item.Path))
+	if _, lookErr := exec.LookPath("sudo"); lookErr != nil || runtime.GOOS == "windows" || m.opts.secure {
+		// sudo rm can't wipe files first, so -secure never offers it
+		return m, true
+	}
+	args := []string{"sudo", "rm", "-f", "--", item.Path}
+	if item.IsDir {
+		args = []string{"sudo", "rm", "-rf", "--", item.Path}
+	}
+	m.sudo = &sudoRetry{item: item, command: args}
+	m.status += fmt.Sprintf(" - press %s to retry with sudo", m.keys.short(actSudo))
+	return m, true
+}
+
+// updateSudo handles the confirmation of a sudo retry
+func (m model) updateSudo(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch {
+	case m.keys.is(msg, actConfirm):
+		cmd := exec.Command(m.sudo.command[0], m.sudo.command[1:]...)
+		item := m.sudo.item
+		m.sudo = nil
+		// sudo may ask for a password, so it gets the terminal
+		return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+			return sudoDoneMsg{item: item, err: err}
+		})
+	case m.keys.is(msg, actCancel), msg.String() == "esc", msg.String() == "ctrl+c":
+		m.sudo = nil
+	}
+	return m, nil
+}
+
+// finishSudo records a deletion done through sudo
+func (m model) finishSudo(msg sudoDoneMsg) model {
+	if msg.err != nil {
+		m.status = fmt.Sprintf("sudo: %v", msg.err)
+		return m
+	}
+	m = m.setSelected(msg.item.Path, false)
+	d := deletion{Path: msg.item.Path, Size: msg.item.Size, Allocated: msg.item.Allocated, Time: time.Now(), Method: removedSudo}
+	m.deleted = append(m.deleted, d)
+	m.status = "Deleted " + filepath.Base(msg.item.Path) + " with sudo"
+	return m.logDeletions([]deletion{d})
+}
+
+// sudoQuestion is shown in place of the status line while confirming
+func (m model) sudoQuestion() string {
+	return m.styles.confirmText.Render(fmt.Sprintf("Run %s? (%s)", printable(shellJoin(m.sudo.command)), m.keys.short(actConfirm, actCancel)))
+}
+
+// shellJoin quotes args into a command line for display
+func shellJoin(args []string) string {
+	s := ""
+	for i, a := range args {
+		if i > 0 {
+			s += " "
+		}
+		s += shellQuote(a)
+	}
+	return s
+}
@@ -0,0 +1,46 @@
+package main
+
+import "os"
+
+// Ways of handling symbolic links selectable with -symlinks. Links are
+// never followed into, so a loop of links can't trap the scan in any mode.
+const (
+	symlinksLink   = "link"   // listed and sized as the link itself, a few bytes
+	symlinksSkip   = "skip"   // left out of the listing and the totals
+	symlinksTarget = "target" // listed, and sized as the file they point to
+)
+
+func validSymlinks(mode string) bool {
+	return mode == symlinksLink || mode == symlinksSkip || mode == symlinksTarget
+}
+
+// linkInfo returns the info an entry is listed and sized with, and false if
+// it's left out. With -symlinks target a link to a file takes that file's
+// info; a link to a folder, a broken link and one in a loop keep their own,
+// as sizing a folder through a link could count it twice or never end.
+func (o scanOptions) linkInfo(path string, info os.FileInfo) (os.FileInfo, bool) {
+	if info.Mode()&os.ModeSymlink == 0 {
+		return info, true
+	}
+	switch o.symlinks {
+	case symlinksSkip:
+		return nil, false
+	case symlinksTarget:
+		if target, err := os.Stat(path); err == nil && target.Mode().IsRegular() {
+			return target, true
+		}
+	}
+	return info, true
+}
+
+// symlinkTitle names the -symlinks mode in the title bar when it isn't the
+// default
+func (m model) symlinkTitle() string {
+	switch m.opts.scan.symlinks {
+	case symlinksSkip:
+		return "- symlinks skipped "
+	case symlinksTarget:
+		return "- symlinks sized by target "
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultTextWidth is the width of -text output when $COLUMNS isn't set
+const defaultTextWidth = 100
+
+// plainStyles renders everything unstyled, for text meant to be embedded
+// in other output
+func plainStyles() styles {
+	plain := lipgloss.NewStyle()
+	return styles{
+		title: plain, header: plain, selected: plain, normal: plain, size: plain,
+		helpText: plain, errorText: plain, confirmText: plain, selectionMark: plain,
+		rootRow: plain, deltaUp: plain, deltaDown: plain, match: plain, banner: plain,
+	}
+}
+
+// renderText lays out the first top rows of a scan as plain, fixed-width
+// text with the same columns as the list, followed by the totals. It works
+// without a terminal, so a scan can be summarized from other programs such
+// as chat bots.
+func renderText(res scanResult, opts options, width, top int) (string, error) {
+	m, err := newModel(res, opts)
+	if err != nil {
+		return "", err
+	}
+	m.styles = plainStyles()
+	m.width = width
+
+	rows := m.rows()
+	cols := m.columns()
+	var s strings.Builder
+	fmt.Fprintf(&s, "%s - %s, %s of %s total\n",
+		printable(cmp.Or(m.opts.typedRoot, m.basePath)), m.viewMode, m.formatSize(m.viewTotal()), m.formatSize(m.grandTotal()))
+	s.WriteString(strings.TrimRight(m.headerLine(cols), " ") + "\n")
+	shown := min(top, rows.len())
+	for row := 0; row < shown; row++ {
+		s.WriteString(strings.TrimRight(m.itemLine(*rows.at(row), cols), " ") + "\n")
+	}
+	if rest := m.cutRest(rows, shown); rest.count > 0 {
+		noun := "items"
+		if rest.count == 1 {
+			noun = "item"
+		}
+		fmt.Fprintf(&s, "... and %d more %s (%s)\n", rest.count, noun, m.formatSize(rest.size))
+	}
+	if opts.timing && res.elapsed > 0 {
+		fmt.Fprintf(&s, "Scanned %s\n", m.scanStats(res))
+	}
+	return s.String(), nil
+}
+
+// cutRest sums up the rows after the first shown, along with any the Pareto
+// view collapsed, so the listed sizes and the rest add up to the view's
+// total. Folders nested in another listed folder are already part of its
+// size and only add to the count.
+func (m model) cutRest(rows rowView, shown int) paretoRest {
+	rest := paretoRest{count: rows.len() - shown + rows.rest.count}
+	visible := make(map[string]bool, rows.len())
+	for row := 0; row < rows.len(); row++ {
+		visible[rows.at(row).Path] = true
+	}
+	var listed int64
+	for row := 0; row < shown; row++ {
+		item := rows.at(row)
+		if item.IsRoot || item.IsLoose {
+			continue
+		}
+		if _, nested := m.visibleParent(item.Path, visible); nested && m.viewMode == "folders" {
+			continue
+		}
+		listed += m.sizeOf(*item)
+	}
+	rest.size = m.viewTotal() - listed
+	return rest
+}
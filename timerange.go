@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayouts are the absolute forms accepted by -since and -until, in local
+// time
+var dateLayouts = []string{"2006-01-02", "2006-01-02 15:04", "2006-01-02T15:04", time.RFC3339}
+
+// parseTimeBound parses a -since or -until value: a date, or a duration
+// before now such as "24h", "7d" or "2w". A bare date given to -until
+// includes the whole day.
+func parseTimeBound(s string, now time.Time, until bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if n, unit := strings.TrimRight(s, "dw"), strings.TrimLeft(s, "0123456789"); unit == "d" || unit == "w" {
+		days, err := strconv.Atoi(n)
+		if err == nil {
+			if unit == "w" {
+				days *= 7
+			}
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range dateLayouts {
+		t, err := time.ParseInLocation(layout, s, time.Local)
+		if err != nil {
+			continue
+		}
+		if until && layout == "2006-01-02" {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is neither a date (2006-01-02) nor a duration (24h, 7d, 2w)", s)
+}
+
+// inRange reports whether a modification time is within -since and -until.
+// Entries outside it are left out of the scan and of every folder size.
+func (o scanOptions) inRange(t time.Time) bool {
+	return (o.since.IsZero() || !t.Before(o.since)) && (o.until.IsZero() || t.Before(o.until))
+}
+
+// timeFiltered reports whether -since or -until narrows the scan
+func (o scanOptions) timeFiltered() bool {
+	return !o.since.IsZero() || !o.until.IsZero()
+}
@@ -0,0 +1,94 @@
+package main
+
+import "fmt"
+
+// Size units selectable with -units and cycled with u
+const (
+	unitsSI    = "si"    // powers of 1000: kB, MB, GB
+	unitsIEC   = "iec"   // powers of 1024: KiB, MiB, GiB
+	unitsExact = "exact" // plain byte counts with digit grouping
+)
+
+var unitCycle = []string{unitsSI, unitsIEC, unitsExact}
+
+// bytesWidth fits "999,999,999,999" bytes
+const bytesWidth = 15
+
+func validUnits(u string) bool {
+	for _, v := range unitCycle {
+		if u == v {
+			return true
+		}
+	}
+	return false
+}
+
+// nextUnits returns the unit mode after u in the cycle
+func nextUnits(u string) string {
+	for i, v := range unitCycle {
+		if v == u {
+			return unitCycle[(i+1)%len(unitCycle)]
+		}
+	}
+	return unitsSI
+}
+
+// formatSize renders n bytes in the active units and locale
+func (m model) formatSize(n int64) string {
+	switch m.opts.units {
+	case unitsExact:
+		return m.loc.comma(n)
+	case unitsIEC:
+		return m.loc.ibytes(uint64(n))
+	}
+	return m.loc.bytes(uint64(n))
+}
+
+// sizeWidth is the SIZE column width needed by the active units
+func (m model) sizeWidth() int {
+	switch m.opts.units {
+	case unitsExact:
+		return bytesWidth
+	case unitsIEC:
+		return 10 // "1023.9 MiB"
+	}
+	return 8 // "999.9 MB"
+}
+
+// bytesColumn renders the secondary BYTES cell with the exact count
+func (m model) bytesColumn(item Item) string {
+	return padLeft(m.loc.comma(m.sizeOf(item)), bytesWidth)
+}
+
+// percentWidth fits "(100.0%)"
+const percentWidth = 8
+
+// share returns the item's fraction of the whole scan. Files are measured
+// against the sum of all listed files; folders against the root's recursive
+// size, which has every file counted once, so nested folders never add up
+// to more than 100%. With -quota, everything is measured against the quota.
+func (m model) share(item Item) float64 {
+	denom := m.grandTotal()
+	if item.IsDir {
+		denom = m.sizeOf(m.root)
+	}
+	if m.opts.quotaBytes > 0 {
+		denom = m.opts.quotaBytes
+	}
+	if denom <= 0 {
+		return 0
+	}
+	return float64(m.sizeOf(item)) / float64(denom)
+}
+
+// sizeText renders the SIZE cell, with the share appended when enabled
+func (m model) sizeText(item Item) string {
+	if item.SizePending {
+		return "..."
+	}
+	text := m.formatSize(m.sizeOf(item))
+	if m.opts.percent {
+		text += " " + padLeft(fmt.Sprintf("(%.1f%%)", m.share(item)*100), percentWidth)
+	}
+	return text
+}
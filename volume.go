@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// volumeCheckInterval is how often the scan root is checked while browsing
+const volumeCheckInterval = 2 * time.Second
+
+// volumeMsg reports whether the scan root at path could still be reached
+type volumeMsg struct {
+	path      string
+	available bool
+}
+
+// watchVolume checks the scan root after an interval, so an unplugged drive
+// is noticed before the next delete or open fails on it
+func watchVolume(path string) tea.Cmd {
+	return tea.Tick(volumeCheckInterval, func(time.Time) tea.Msg {
+		return volumeMsg{path: path, available: volumeAvailable(path)}
+	})
+}
+
+func volumeAvailable(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// checkVolume marks the volume gone when the scan root can't be reached.
+// It reports whether it's still there.
+func (m model) checkVolume() (model, bool) {
+	if m.opts.fromNDJSON {
+		return m, true
+	}
+	m.gone = !volumeAvailable(m.basePath)
+	return m, !m.gone
+}
+
+// scanPath scans path in the background, replacing the current data with
+// the result
+func (m model) scanPath(path string) (model, tea.Cmd) {
+	m.status = "Scanning " + path + "..."
+	m.scanning = &scanProgressMsg{}
+	return m, backgroundScan(path, m.opts, func(res scanResult, err error) tea.Msg {
+		return rescanMsg{res: res, err: err}
+	})
+}
+
+// promptRescan asks for another folder to scan
+func (m model) promptRescan() model {
+	m.prompt = &prompt{
+		label: "Scan path: ",
+		onSubmit: func(m model, value string) (model, tea.Cmd) {
+			if value == "" {
+				return m, nil
+			}
+			return m.scanPath(value)
+		},
+	}
+	return m
+}
+
+// updateGone handles keys while the volume is unavailable
+func (m model) updateGone(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case m.keys.is(msg, actQuit):
+		return m, tea.Quit
+	case m.keys.is(msg, actRescan):
+		return m.scanPath(m.basePath)
+	case m.keys.is(msg, actPath):
+		m = m.promptRescan()
+	}
+	return m, nil
+}
+
+// goneView explains that the scanned folder disappeared, typically because
+// the drive holding it was unplugged
+func (m model) goneView() string {
+	var s strings.Builder
+	s.WriteString(m.styles.title.Render(" Disk Usage Analyzer - VOLUME NO LONGER AVAILABLE ") + "\n\n")
+	s.WriteString(m.styles.normal.Render(printable(m.basePath)+" can't be reached.") + "\n")
+	s.WriteString(m.styles.normal.Render("The drive may have been unplugged or unmounted.") + "\n")
+	if m.prompt != nil {
+		s.WriteString("\n" + m.prompt.view(m.styles) + "\n")
+	} else if m.status != "" {
+		s.WriteString("\n" + m.styles.helpText.Render(printable(m.status)) + "\n")
+	}
+	s.WriteString(m.styles.helpText.Render("\n" + m.keys.hints(
+		hint{"Rescan when it's back", []action{actRescan}},
+		hint{"Scan another path", []action{actPath}},
+		hint{"Quit", []action{actQuit}},
+	)))
+
+	block := lipgloss.JoinVertical(lipgloss.Left, strings.Split(s.String(), "\n")...)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, block)
+}